@@ -6,6 +6,7 @@ package main // import "github.com/carsonoid/kube-crds-and-controllers/hard-code
 import (
 	"encoding/json"
 	"flag"
+	"fmt"
 	logging "log"
 	"os"
 	"path/filepath"
@@ -40,8 +41,19 @@ const (
 type PodLabelConfig struct {
 	TargetNamespace string            `json:"targetNamespace"`
 	Labels          map[string]string `json:"labels"`
+	// Mode is one of Enforce, IfAbsent or Audit. Defaults to Enforce
+	Mode string `json:"mode"`
 }
 
+const (
+	// ModeEnforce overwrites labels with a different value
+	ModeEnforce string = "Enforce"
+	// ModeIfAbsent only adds missing labels, labels with a different value are logged and left alone
+	ModeIfAbsent string = "IfAbsent"
+	// ModeAudit only logs the labels that would be set
+	ModeAudit string = "Audit"
+)
+
 // PodLabelController with a config and client
 type PodLabelController struct {
 	client         *kubernetes.Clientset
@@ -154,6 +166,10 @@ func (plc *PodLabelController) labelPod(pod *corev1.Pod) bool {
 			for k, newVal := range c.Labels {
 				if curVal, ok := pod.GetLabels()[k]; ok && curVal == newVal {
 					//log.Printf("Pod %s already has label: %s=%s", pod.GetName(), k, newVal)
+				} else if ok && c.Mode == ModeIfAbsent {
+					log.Printf("Pod %s keeps label: %s=%s, config wants %s", pod.GetName(), k, curVal, newVal)
+				} else if c.Mode == ModeAudit {
+					log.Printf("Audit: pod %s would get label: %s=%s", pod.GetName(), k, newVal)
 				} else {
 					log.Printf("Pod %s needs label: %s=%s", pod.GetName(), k, newVal)
 					pod.Labels[k] = newVal
@@ -212,6 +228,9 @@ func (plc *PodLabelController) loadConfigMap(cm *corev1.ConfigMap) error {
 		if err := yaml.Unmarshal([]byte(v), &c); err != nil {
 			return err
 		}
+		if err := validateMode(c.Mode); err != nil {
+			return fmt.Errorf("config %s: %s", k, err)
+		}
 
 		// Update map with pointer to struct
 		plc.Configs[k] = &c
@@ -226,6 +245,15 @@ func (plc *PodLabelController) loadConfigMap(cm *corev1.ConfigMap) error {
 	return nil
 }
 
+// validateMode checks that a config uses a known mode
+func validateMode(mode string) error {
+	switch mode {
+	case "", ModeEnforce, ModeIfAbsent, ModeAudit:
+		return nil
+	}
+	return fmt.Errorf("invalid mode %q, must be Enforce, IfAbsent or Audit", mode)
+}
+
 func main() {
 	log.SetOutput(os.Stdout)
 
@@ -18,13 +18,23 @@ const (
 	PodLabelConfigResourceKind       = "PodLabelConfig"
 	PodLabelConfigResourceName       = "podlabelconfig"
 	PodLabelConfigResourceNamePlural = "podlabelconfigs"
+
+	ClusterPodLabelConfigResourceKind       = "ClusterPodLabelConfig"
+	ClusterPodLabelConfigResourceName       = "clusterpodlabelconfig"
+	ClusterPodLabelConfigResourceNamePlural = "clusterpodlabelconfigs"
+
+	ResourceLabelConfigResourceKind       = "ResourceLabelConfig"
+	ResourceLabelConfigResourceName       = "resourcelabelconfig"
+	ResourceLabelConfigResourceNamePlural = "resourcelabelconfigs"
 )
 
 var (
 	// SchemeGroupVersion is the group version used to register these objects.
 	SchemeGroupVersion = schema.GroupVersion{Group: GroupName, Version: V1alpha1}
 
-	PodLabelConfigCRDName = PodLabelConfigResourceNamePlural + "." + GroupName
+	PodLabelConfigCRDName        = PodLabelConfigResourceNamePlural + "." + GroupName
+	ClusterPodLabelConfigCRDName = ClusterPodLabelConfigResourceNamePlural + "." + GroupName
+	ResourceLabelConfigCRDName   = ResourceLabelConfigResourceNamePlural + "." + GroupName
 )
 
 // Kind takes an unqualified kind and returns back a Group qualified GroupKind
@@ -42,6 +52,10 @@ func addKnownTypes(scheme *runtime.Scheme) error {
 	scheme.AddKnownTypes(SchemeGroupVersion,
 		&PodLabelConfig{},
 		&PodLabelConfigList{},
+		&ClusterPodLabelConfig{},
+		&ClusterPodLabelConfigList{},
+		&ResourceLabelConfig{},
+		&ResourceLabelConfigList{},
 	)
 	metav1.AddToGroupVersion(scheme, SchemeGroupVersion)
 	return nil
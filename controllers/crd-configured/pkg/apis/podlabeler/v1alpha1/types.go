@@ -1,14 +1,23 @@
 package v1alpha1
 
 import (
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 // -------------------------------------------------------------------------------- PodLabelConfig
 // generation tags. The empty line after is IMPORTANT!
 // +genclient
-// +genclient:noStatus
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:resource:shortName=plc
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Priority",type=integer,JSONPath=`.spec.priority`
+// +kubebuilder:printcolumn:name="Mode",type=string,JSONPath=`.spec.mode`
+// +kubebuilder:printcolumn:name="Matched",type=integer,JSONPath=`.status.matchedPods`
+// +kubebuilder:printcolumn:name="Patched",type=integer,JSONPath=`.status.patchedPods`
+// +kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+// +kubebuilder:storageversion
 
 // PodLabelConfig represents a set of labels to be applied to pods in a namespace
 type PodLabelConfig struct {
@@ -18,12 +27,113 @@ type PodLabelConfig struct {
 
 	// Spec defines the config
 	Spec PodLabelConfigSpec `json:"spec,omitempty"`
+
+	// Status describes the observed state of the config
+	// +optional
+	Status PodLabelConfigStatus `json:"status,omitempty"`
 }
 
-// PodLabelConfigSpec describes the labels to apply to all pods in a namespace
+// PodLabelConfigSpec describes the labels and annotations to apply to the selected pods in a namespace
 type PodLabelConfigSpec struct {
 	// Labels is a map of the labels to be applied to pods in the namespace
 	Labels map[string]string `json:"labels,omitempty"`
+
+	// Annotations is a map of the annotations to be applied to pods in the namespace
+	// +optional
+	Annotations map[string]string `json:"annotations,omitempty"`
+
+	// PodSelector limits the pods in the namespace that the labels are applied to.
+	// A missing selector selects every pod in the namespace
+	// +optional
+	PodSelector *metav1.LabelSelector `json:"podSelector,omitempty"`
+
+	// Priority decides which config wins when several configs set the same key on a pod.
+	// The config with the highest priority wins. Configs with equal priority are ordered by name
+	// and the first name wins.
+	// +optional
+	Priority int32 `json:"priority,omitempty"`
+
+	// Mode decides if the config overwrites existing values, only adds missing keys or only reports
+	// the changes it would make. Defaults to Enforce
+	// +optional
+	Mode PodLabelConfigMode `json:"mode,omitempty"`
+
+	// PodTemplates also applies the labels and annotations to the pod templates of the Deployments,
+	// StatefulSets, DaemonSets and CronJobs selected by the config, so new pods are created with them.
+	// Templated label values are only applied to pods
+	// +optional
+	PodTemplates bool `json:"podTemplates,omitempty"`
+}
+
+// PodLabelConfigMode is one of Enforce, IfAbsent or Audit
+// +kubebuilder:validation:Enum=Enforce;IfAbsent;Audit
+type PodLabelConfigMode string
+
+const (
+	// PodLabelConfigModeEnforce patches pods with the labels and annotations of the config
+	PodLabelConfigModeEnforce PodLabelConfigMode = "Enforce"
+	// PodLabelConfigModeIfAbsent only sets keys a pod does not have yet. Existing values set by anything
+	// else are reported with an event but never overwritten
+	PodLabelConfigModeIfAbsent PodLabelConfigMode = "IfAbsent"
+	// PodLabelConfigModeAudit only logs and records an event for the patches the config would make
+	PodLabelConfigModeAudit PodLabelConfigMode = "Audit"
+)
+
+// PodLabelConfigConditionType is one of Ready, Conflicting or Error
+// +kubebuilder:validation:Enum=Ready;Conflicting;Error
+type PodLabelConfigConditionType string
+
+const (
+	// PodLabelConfigReady is true when the current generation of the config was applied to all matched pods
+	PodLabelConfigReady PodLabelConfigConditionType = "Ready"
+	// PodLabelConfigConflicting is true when a config with precedence sets one of the same keys to a different value
+	PodLabelConfigConflicting PodLabelConfigConditionType = "Conflicting"
+	// PodLabelConfigError is true when the config could not be applied, the message holds the last error
+	PodLabelConfigError PodLabelConfigConditionType = "Error"
+)
+
+// PodLabelConfigCondition describes the state of a config at a certain point
+type PodLabelConfigCondition struct {
+	// Type of the condition
+	Type PodLabelConfigConditionType `json:"type"`
+
+	// Status of the condition, one of True, False, Unknown
+	Status corev1.ConditionStatus `json:"status"`
+
+	// LastTransitionTime is the last time the condition changed status
+	// +optional
+	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty"`
+
+	// Reason is a one-word CamelCase reason for the condition's last transition
+	// +optional
+	Reason string `json:"reason,omitempty"`
+
+	// Message is a human readable description of the details of the last transition
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// PodLabelConfigStatus describes the observed state of a config
+type PodLabelConfigStatus struct {
+	// ObservedGeneration is the generation of the config that was last reconciled
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// MatchedPods is the number of pods selected by the config
+	// +optional
+	MatchedPods int32 `json:"matchedPods,omitempty"`
+
+	// PatchedPods is the number of selected pods that carry labels or annotations set by the config
+	// +optional
+	PatchedPods int32 `json:"patchedPods,omitempty"`
+
+	// LastReconcileTime is the last time all pods of the config were reconciled
+	// +optional
+	LastReconcileTime *metav1.Time `json:"lastReconcileTime,omitempty"`
+
+	// Conditions is the current set of conditions for the config
+	// +optional
+	Conditions []PodLabelConfigCondition `json:"conditions,omitempty"`
 }
 
 // generation tags. The empty line after is IMPORTANT!
@@ -37,3 +147,122 @@ type PodLabelConfigList struct {
 
 	Items []PodLabelConfig `json:"items"`
 }
+
+// -------------------------------------------------------------------------------- ClusterPodLabelConfig
+// generation tags. The empty line after is IMPORTANT!
+// +genclient
+// +genclient:nonNamespaced
+// +genclient:noStatus
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:resource:scope=Cluster,shortName=cplc
+// +kubebuilder:printcolumn:name="Priority",type=integer,JSONPath=`.spec.priority`
+// +kubebuilder:printcolumn:name="Mode",type=string,JSONPath=`.spec.mode`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// ClusterPodLabelConfig represents a set of labels to be applied to pods in every namespace matching a selector
+type ClusterPodLabelConfig struct {
+	metav1.TypeMeta `json:",inline"`
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// Spec defines the config
+	Spec ClusterPodLabelConfigSpec `json:"spec,omitempty"`
+}
+
+// ClusterPodLabelConfigSpec describes the labels and annotations to apply to the selected pods in the selected namespaces
+type ClusterPodLabelConfigSpec struct {
+	// The labels, annotations, pod selector and priority behave the same as they do for a PodLabelConfig
+	PodLabelConfigSpec `json:",inline"`
+
+	// NamespaceSelector selects the namespaces the config applies to.
+	// A missing selector selects every namespace
+	// +optional
+	NamespaceSelector *metav1.LabelSelector `json:"namespaceSelector,omitempty"`
+}
+
+// generation tags. The empty line after is IMPORTANT!
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// ClusterPodLabelConfigList is a list of ClusterPodLabelConfigs
+type ClusterPodLabelConfigList struct {
+	metav1.TypeMeta `json:",inline"`
+	// +optional
+	metav1.ListMeta `json:"metadata"`
+
+	Items []ClusterPodLabelConfig `json:"items"`
+}
+
+// -------------------------------------------------------------------------------- ResourceLabelConfig
+// generation tags. The empty line after is IMPORTANT!
+// +genclient
+// +genclient:nonNamespaced
+// +genclient:noStatus
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:resource:scope=Cluster,shortName=rlc
+// +kubebuilder:printcolumn:name="Group",type=string,JSONPath=`.spec.group`
+// +kubebuilder:printcolumn:name="Resource",type=string,JSONPath=`.spec.resource`
+// +kubebuilder:printcolumn:name="Priority",type=integer,JSONPath=`.spec.priority`
+// +kubebuilder:printcolumn:name="Mode",type=string,JSONPath=`.spec.mode`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// ResourceLabelConfig represents a set of labels to be applied to every object of any resource kind
+type ResourceLabelConfig struct {
+	metav1.TypeMeta `json:",inline"`
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// Spec defines the config
+	Spec ResourceLabelConfigSpec `json:"spec,omitempty"`
+}
+
+// ResourceLabelConfigSpec describes the labels and annotations to apply to the selected objects of a resource
+type ResourceLabelConfigSpec struct {
+	// Group is the API group of the resource, empty for the core group
+	// +optional
+	Group string `json:"group,omitempty"`
+
+	// Version is the API version of the resource, for example v1
+	Version string `json:"version"`
+
+	// Resource is the plural name of the resource, for example services or persistentvolumeclaims.
+	// Pods are labelled by PodLabelConfigs and ClusterPodLabelConfigs instead
+	Resource string `json:"resource"`
+
+	// Labels is a map of the labels to be applied to the selected objects.
+	// Templated values are only supported for pods
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// Annotations is a map of the annotations to be applied to the selected objects
+	// +optional
+	Annotations map[string]string `json:"annotations,omitempty"`
+
+	// Selector limits the objects that the labels are applied to.
+	// A missing selector selects every object of the resource
+	// +optional
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+
+	// NamespaceSelector limits namespaced resources to the namespaces it selects.
+	// A missing selector selects every namespace. It is ignored for cluster-scoped resources
+	// +optional
+	NamespaceSelector *metav1.LabelSelector `json:"namespaceSelector,omitempty"`
+
+	// Priority and Mode behave the same as they do for a PodLabelConfig, between the
+	// ResourceLabelConfigs of the same resource
+	// +optional
+	Priority int32 `json:"priority,omitempty"`
+
+	// +optional
+	Mode PodLabelConfigMode `json:"mode,omitempty"`
+}
+
+// generation tags. The empty line after is IMPORTANT!
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// ResourceLabelConfigList is a list of ResourceLabelConfigs
+type ResourceLabelConfigList struct {
+	metav1.TypeMeta `json:",inline"`
+	// +optional
+	metav1.ListMeta `json:"metadata"`
+
+	Items []ResourceLabelConfig `json:"items"`
+}
@@ -21,6 +21,7 @@ limitations under the License.
 package v1alpha1
 
 import (
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	conversion "k8s.io/apimachinery/pkg/conversion"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 	reflect "reflect"
@@ -36,10 +37,26 @@ func init() {
 // Deprecated: deepcopy registration will go away when static deepcopy is fully implemented.
 func RegisterDeepCopies(scheme *runtime.Scheme) error {
 	return scheme.AddGeneratedDeepCopyFuncs(
+		conversion.GeneratedDeepCopyFunc{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
+			in.(*ClusterPodLabelConfig).DeepCopyInto(out.(*ClusterPodLabelConfig))
+			return nil
+		}, InType: reflect.TypeOf(&ClusterPodLabelConfig{})},
+		conversion.GeneratedDeepCopyFunc{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
+			in.(*ClusterPodLabelConfigList).DeepCopyInto(out.(*ClusterPodLabelConfigList))
+			return nil
+		}, InType: reflect.TypeOf(&ClusterPodLabelConfigList{})},
+		conversion.GeneratedDeepCopyFunc{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
+			in.(*ClusterPodLabelConfigSpec).DeepCopyInto(out.(*ClusterPodLabelConfigSpec))
+			return nil
+		}, InType: reflect.TypeOf(&ClusterPodLabelConfigSpec{})},
 		conversion.GeneratedDeepCopyFunc{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
 			in.(*PodLabelConfig).DeepCopyInto(out.(*PodLabelConfig))
 			return nil
 		}, InType: reflect.TypeOf(&PodLabelConfig{})},
+		conversion.GeneratedDeepCopyFunc{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
+			in.(*PodLabelConfigCondition).DeepCopyInto(out.(*PodLabelConfigCondition))
+			return nil
+		}, InType: reflect.TypeOf(&PodLabelConfigCondition{})},
 		conversion.GeneratedDeepCopyFunc{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
 			in.(*PodLabelConfigList).DeepCopyInto(out.(*PodLabelConfigList))
 			return nil
@@ -48,15 +65,120 @@ func RegisterDeepCopies(scheme *runtime.Scheme) error {
 			in.(*PodLabelConfigSpec).DeepCopyInto(out.(*PodLabelConfigSpec))
 			return nil
 		}, InType: reflect.TypeOf(&PodLabelConfigSpec{})},
+		conversion.GeneratedDeepCopyFunc{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
+			in.(*PodLabelConfigStatus).DeepCopyInto(out.(*PodLabelConfigStatus))
+			return nil
+		}, InType: reflect.TypeOf(&PodLabelConfigStatus{})},
+		conversion.GeneratedDeepCopyFunc{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
+			in.(*ResourceLabelConfig).DeepCopyInto(out.(*ResourceLabelConfig))
+			return nil
+		}, InType: reflect.TypeOf(&ResourceLabelConfig{})},
+		conversion.GeneratedDeepCopyFunc{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
+			in.(*ResourceLabelConfigList).DeepCopyInto(out.(*ResourceLabelConfigList))
+			return nil
+		}, InType: reflect.TypeOf(&ResourceLabelConfigList{})},
+		conversion.GeneratedDeepCopyFunc{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
+			in.(*ResourceLabelConfigSpec).DeepCopyInto(out.(*ResourceLabelConfigSpec))
+			return nil
+		}, InType: reflect.TypeOf(&ResourceLabelConfigSpec{})},
 	)
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterPodLabelConfig) DeepCopyInto(out *ClusterPodLabelConfig) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterPodLabelConfig.
+func (in *ClusterPodLabelConfig) DeepCopy() *ClusterPodLabelConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterPodLabelConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ClusterPodLabelConfig) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	} else {
+		return nil
+	}
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterPodLabelConfigList) DeepCopyInto(out *ClusterPodLabelConfigList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	out.ListMeta = in.ListMeta
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ClusterPodLabelConfig, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterPodLabelConfigList.
+func (in *ClusterPodLabelConfigList) DeepCopy() *ClusterPodLabelConfigList {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterPodLabelConfigList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ClusterPodLabelConfigList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	} else {
+		return nil
+	}
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterPodLabelConfigSpec) DeepCopyInto(out *ClusterPodLabelConfigSpec) {
+	*out = *in
+	in.PodLabelConfigSpec.DeepCopyInto(&out.PodLabelConfigSpec)
+	if in.NamespaceSelector != nil {
+		in, out := &in.NamespaceSelector, &out.NamespaceSelector
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(v1.LabelSelector)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterPodLabelConfigSpec.
+func (in *ClusterPodLabelConfigSpec) DeepCopy() *ClusterPodLabelConfigSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterPodLabelConfigSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PodLabelConfig) DeepCopyInto(out *PodLabelConfig) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
 	return
 }
 
@@ -79,6 +201,23 @@ func (in *PodLabelConfig) DeepCopyObject() runtime.Object {
 	}
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PodLabelConfigCondition) DeepCopyInto(out *PodLabelConfigCondition) {
+	*out = *in
+	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PodLabelConfigCondition.
+func (in *PodLabelConfigCondition) DeepCopy() *PodLabelConfigCondition {
+	if in == nil {
+		return nil
+	}
+	out := new(PodLabelConfigCondition)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PodLabelConfigList) DeepCopyInto(out *PodLabelConfigList) {
 	*out = *in
@@ -123,6 +262,22 @@ func (in *PodLabelConfigSpec) DeepCopyInto(out *PodLabelConfigSpec) {
 			(*out)[key] = val
 		}
 	}
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.PodSelector != nil {
+		in, out := &in.PodSelector, &out.PodSelector
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(v1.LabelSelector)
+			(*in).DeepCopyInto(*out)
+		}
+	}
 	return
 }
 
@@ -135,3 +290,145 @@ func (in *PodLabelConfigSpec) DeepCopy() *PodLabelConfigSpec {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PodLabelConfigStatus) DeepCopyInto(out *PodLabelConfigStatus) {
+	*out = *in
+	if in.LastReconcileTime != nil {
+		in, out := &in.LastReconcileTime, &out.LastReconcileTime
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(v1.Time)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]PodLabelConfigCondition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PodLabelConfigStatus.
+func (in *PodLabelConfigStatus) DeepCopy() *PodLabelConfigStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(PodLabelConfigStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResourceLabelConfig) DeepCopyInto(out *ResourceLabelConfig) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceLabelConfig.
+func (in *ResourceLabelConfig) DeepCopy() *ResourceLabelConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(ResourceLabelConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ResourceLabelConfig) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	} else {
+		return nil
+	}
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResourceLabelConfigList) DeepCopyInto(out *ResourceLabelConfigList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	out.ListMeta = in.ListMeta
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ResourceLabelConfig, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceLabelConfigList.
+func (in *ResourceLabelConfigList) DeepCopy() *ResourceLabelConfigList {
+	if in == nil {
+		return nil
+	}
+	out := new(ResourceLabelConfigList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ResourceLabelConfigList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	} else {
+		return nil
+	}
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResourceLabelConfigSpec) DeepCopyInto(out *ResourceLabelConfigSpec) {
+	*out = *in
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Selector != nil {
+		in, out := &in.Selector, &out.Selector
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(v1.LabelSelector)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.NamespaceSelector != nil {
+		in, out := &in.NamespaceSelector, &out.NamespaceSelector
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(v1.LabelSelector)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceLabelConfigSpec.
+func (in *ResourceLabelConfigSpec) DeepCopy() *ResourceLabelConfigSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ResourceLabelConfigSpec)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -0,0 +1,125 @@
+package v1beta1
+
+import (
+	"encoding/json"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/carsonoid/kube-crds-and-controllers/controllers/crd-configured/pkg/apis/podlabeler/v1alpha1"
+)
+
+// The conversions are written by hand, both versions hold the same information so every object survives a round
+// trip. Only the podSelector of v1alpha1 was renamed to selector.
+
+// Convert_v1alpha1_PodLabelConfig_To_v1beta1_PodLabelConfig converts a v1alpha1 PodLabelConfig to v1beta1
+func Convert_v1alpha1_PodLabelConfig_To_v1beta1_PodLabelConfig(in *v1alpha1.PodLabelConfig, out *PodLabelConfig) error {
+	in = in.DeepCopy()
+
+	out.TypeMeta = metav1.TypeMeta{APIVersion: SchemeGroupVersion.String(), Kind: PodLabelConfigResourceKind}
+	out.ObjectMeta = in.ObjectMeta
+	out.Spec = PodLabelConfigSpec{
+		Labels:       in.Spec.Labels,
+		Annotations:  in.Spec.Annotations,
+		Selector:     in.Spec.PodSelector,
+		Priority:     in.Spec.Priority,
+		Mode:         PodLabelConfigMode(in.Spec.Mode),
+		PodTemplates: in.Spec.PodTemplates,
+	}
+	out.Status = PodLabelConfigStatus{
+		ObservedGeneration: in.Status.ObservedGeneration,
+		MatchedPods:        in.Status.MatchedPods,
+		PatchedPods:        in.Status.PatchedPods,
+		LastReconcileTime:  in.Status.LastReconcileTime,
+	}
+	for _, c := range in.Status.Conditions {
+		out.Status.Conditions = append(out.Status.Conditions, PodLabelConfigCondition{
+			Type:               PodLabelConfigConditionType(c.Type),
+			Status:             c.Status,
+			LastTransitionTime: c.LastTransitionTime,
+			Reason:             c.Reason,
+			Message:            c.Message,
+		})
+	}
+	return nil
+}
+
+// Convert_v1beta1_PodLabelConfig_To_v1alpha1_PodLabelConfig converts a v1beta1 PodLabelConfig to v1alpha1
+func Convert_v1beta1_PodLabelConfig_To_v1alpha1_PodLabelConfig(in *PodLabelConfig, out *v1alpha1.PodLabelConfig) error {
+	in = in.DeepCopy()
+
+	out.TypeMeta = metav1.TypeMeta{APIVersion: v1alpha1.SchemeGroupVersion.String(), Kind: v1alpha1.PodLabelConfigResourceKind}
+	out.ObjectMeta = in.ObjectMeta
+	out.Spec = v1alpha1.PodLabelConfigSpec{
+		Labels:       in.Spec.Labels,
+		Annotations:  in.Spec.Annotations,
+		PodSelector:  in.Spec.Selector,
+		Priority:     in.Spec.Priority,
+		Mode:         v1alpha1.PodLabelConfigMode(in.Spec.Mode),
+		PodTemplates: in.Spec.PodTemplates,
+	}
+	out.Status = v1alpha1.PodLabelConfigStatus{
+		ObservedGeneration: in.Status.ObservedGeneration,
+		MatchedPods:        in.Status.MatchedPods,
+		PatchedPods:        in.Status.PatchedPods,
+		LastReconcileTime:  in.Status.LastReconcileTime,
+	}
+	for _, c := range in.Status.Conditions {
+		out.Status.Conditions = append(out.Status.Conditions, v1alpha1.PodLabelConfigCondition{
+			Type:               v1alpha1.PodLabelConfigConditionType(c.Type),
+			Status:             c.Status,
+			LastTransitionTime: c.LastTransitionTime,
+			Reason:             c.Reason,
+			Message:            c.Message,
+		})
+	}
+	return nil
+}
+
+// ConvertPodLabelConfig converts a raw PodLabelConfig between v1alpha1 and v1beta1, as the conversion webhook does.
+// Objects already in the desired version are returned unchanged.
+func ConvertPodLabelConfig(object runtime.RawExtension, desiredAPIVersion string) (runtime.RawExtension, error) {
+	typeMeta := metav1.TypeMeta{}
+	if err := json.Unmarshal(object.Raw, &typeMeta); err != nil {
+		return object, err
+	}
+	if typeMeta.Kind != v1alpha1.PodLabelConfigResourceKind {
+		return object, fmt.Errorf("unexpected kind %q", typeMeta.Kind)
+	}
+	if typeMeta.APIVersion == desiredAPIVersion {
+		return object, nil
+	}
+
+	var out interface{}
+	switch {
+	case typeMeta.APIVersion == v1alpha1.SchemeGroupVersion.String() && desiredAPIVersion == SchemeGroupVersion.String():
+		in := &v1alpha1.PodLabelConfig{}
+		if err := json.Unmarshal(object.Raw, in); err != nil {
+			return object, err
+		}
+		c := &PodLabelConfig{}
+		if err := Convert_v1alpha1_PodLabelConfig_To_v1beta1_PodLabelConfig(in, c); err != nil {
+			return object, err
+		}
+		out = c
+	case typeMeta.APIVersion == SchemeGroupVersion.String() && desiredAPIVersion == v1alpha1.SchemeGroupVersion.String():
+		in := &PodLabelConfig{}
+		if err := json.Unmarshal(object.Raw, in); err != nil {
+			return object, err
+		}
+		c := &v1alpha1.PodLabelConfig{}
+		if err := Convert_v1beta1_PodLabelConfig_To_v1alpha1_PodLabelConfig(in, c); err != nil {
+			return object, err
+		}
+		out = c
+	default:
+		return object, fmt.Errorf("cannot convert PodLabelConfig from %q to %q", typeMeta.APIVersion, desiredAPIVersion)
+	}
+
+	raw, err := json.Marshal(out)
+	if err != nil {
+		return object, err
+	}
+	return runtime.RawExtension{Raw: raw}, nil
+}
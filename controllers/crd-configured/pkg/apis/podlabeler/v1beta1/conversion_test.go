@@ -0,0 +1,233 @@
+package v1beta1
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/carsonoid/kube-crds-and-controllers/controllers/crd-configured/pkg/apis/podlabeler/v1alpha1"
+)
+
+// Whole seconds in UTC, so the times survive the json round trips of the raw conversions
+var (
+	reconciled = metav1.Date(2018, time.March, 1, 12, 0, 0, 0, time.UTC)
+	transition = metav1.Date(2018, time.February, 28, 8, 30, 0, 0, time.UTC)
+)
+
+func testObjectMeta() metav1.ObjectMeta {
+	return metav1.ObjectMeta{
+		Name:            "test",
+		Namespace:       "default",
+		UID:             "8d3c4a52-1d2e-11e8-b467-0ed5f89f718b",
+		ResourceVersion: "1234",
+		Generation:      3,
+		Labels:          map[string]string{"team": "web"},
+		Annotations:     map[string]string{"note": "every field set"},
+		Finalizers:      []string{"podlabelconfig.finalizers.k8s.carsonoid.net"},
+	}
+}
+
+func testSelector() *metav1.LabelSelector {
+	return &metav1.LabelSelector{
+		MatchLabels: map[string]string{"app": "web"},
+		MatchExpressions: []metav1.LabelSelectorRequirement{
+			{Key: "tier", Operator: metav1.LabelSelectorOpNotIn, Values: []string{"cache", "db"}},
+			{Key: "canary", Operator: metav1.LabelSelectorOpDoesNotExist},
+		},
+	}
+}
+
+// alphaConfig and betaConfig are the same PodLabelConfig with every field set, in each version
+func alphaConfig() *v1alpha1.PodLabelConfig {
+	return &v1alpha1.PodLabelConfig{
+		TypeMeta:   metav1.TypeMeta{APIVersion: v1alpha1.SchemeGroupVersion.String(), Kind: v1alpha1.PodLabelConfigResourceKind},
+		ObjectMeta: testObjectMeta(),
+		Spec: v1alpha1.PodLabelConfigSpec{
+			Labels:       map[string]string{"owner": "web-team", "image": "{{ .imageTag }}"},
+			Annotations:  map[string]string{"contact": "web@example.com"},
+			PodSelector:  testSelector(),
+			Priority:     10,
+			Mode:         v1alpha1.PodLabelConfigModeIfAbsent,
+			PodTemplates: true,
+		},
+		Status: v1alpha1.PodLabelConfigStatus{
+			ObservedGeneration: 3,
+			MatchedPods:        4,
+			PatchedPods:        2,
+			LastReconcileTime:  &reconciled,
+			Conditions: []v1alpha1.PodLabelConfigCondition{
+				{Type: v1alpha1.PodLabelConfigReady, Status: corev1.ConditionTrue, LastTransitionTime: transition, Reason: "Reconciled"},
+				{Type: v1alpha1.PodLabelConfigConflicting, Status: corev1.ConditionTrue, LastTransitionTime: transition, Reason: "KeyConflict", Message: "Overridden by configs with precedence: other"},
+			},
+		},
+	}
+}
+
+func betaConfig() *PodLabelConfig {
+	return &PodLabelConfig{
+		TypeMeta:   metav1.TypeMeta{APIVersion: SchemeGroupVersion.String(), Kind: PodLabelConfigResourceKind},
+		ObjectMeta: testObjectMeta(),
+		Spec: PodLabelConfigSpec{
+			Labels:       map[string]string{"owner": "web-team", "image": "{{ .imageTag }}"},
+			Annotations:  map[string]string{"contact": "web@example.com"},
+			Selector:     testSelector(),
+			Priority:     10,
+			Mode:         PodLabelConfigModeIfAbsent,
+			PodTemplates: true,
+		},
+		Status: PodLabelConfigStatus{
+			ObservedGeneration: 3,
+			MatchedPods:        4,
+			PatchedPods:        2,
+			LastReconcileTime:  &reconciled,
+			Conditions: []PodLabelConfigCondition{
+				{Type: PodLabelConfigReady, Status: corev1.ConditionTrue, LastTransitionTime: transition, Reason: "Reconciled"},
+				{Type: PodLabelConfigConflicting, Status: corev1.ConditionTrue, LastTransitionTime: transition, Reason: "KeyConflict", Message: "Overridden by configs with precedence: other"},
+			},
+		},
+	}
+}
+
+func TestConvertV1alpha1RoundTrip(t *testing.T) {
+	in := alphaConfig()
+
+	beta := &PodLabelConfig{}
+	if err := Convert_v1alpha1_PodLabelConfig_To_v1beta1_PodLabelConfig(in, beta); err != nil {
+		t.Fatalf("converting to v1beta1: %s", err)
+	}
+	if !equality.Semantic.DeepEqual(beta, betaConfig()) {
+		t.Errorf("v1beta1 = %#v, want %#v", beta, betaConfig())
+	}
+
+	alpha := &v1alpha1.PodLabelConfig{}
+	if err := Convert_v1beta1_PodLabelConfig_To_v1alpha1_PodLabelConfig(beta, alpha); err != nil {
+		t.Fatalf("converting back to v1alpha1: %s", err)
+	}
+	if !equality.Semantic.DeepEqual(alpha, alphaConfig()) {
+		t.Errorf("round trip = %#v, want %#v", alpha, alphaConfig())
+	}
+
+	// The input is copied, changing the result must not change it
+	beta.Spec.Selector.MatchLabels["app"] = "changed"
+	if !equality.Semantic.DeepEqual(in, alphaConfig()) {
+		t.Errorf("input changed to %#v", in)
+	}
+}
+
+func TestConvertV1beta1RoundTrip(t *testing.T) {
+	in := betaConfig()
+
+	alpha := &v1alpha1.PodLabelConfig{}
+	if err := Convert_v1beta1_PodLabelConfig_To_v1alpha1_PodLabelConfig(in, alpha); err != nil {
+		t.Fatalf("converting to v1alpha1: %s", err)
+	}
+	if !equality.Semantic.DeepEqual(alpha, alphaConfig()) {
+		t.Errorf("v1alpha1 = %#v, want %#v", alpha, alphaConfig())
+	}
+
+	beta := &PodLabelConfig{}
+	if err := Convert_v1alpha1_PodLabelConfig_To_v1beta1_PodLabelConfig(alpha, beta); err != nil {
+		t.Fatalf("converting back to v1beta1: %s", err)
+	}
+	if !equality.Semantic.DeepEqual(beta, betaConfig()) {
+		t.Errorf("round trip = %#v, want %#v", beta, betaConfig())
+	}
+
+	alpha.Spec.PodSelector.MatchLabels["app"] = "changed"
+	if !equality.Semantic.DeepEqual(in, betaConfig()) {
+		t.Errorf("input changed to %#v", in)
+	}
+}
+
+func mustMarshal(t *testing.T, obj interface{}) runtime.RawExtension {
+	raw, err := json.Marshal(obj)
+	if err != nil {
+		t.Fatalf("encoding %T: %s", obj, err)
+	}
+	return runtime.RawExtension{Raw: raw}
+}
+
+func TestConvertPodLabelConfig(t *testing.T) {
+	wrongKind := alphaConfig()
+	wrongKind.Kind = v1alpha1.ClusterPodLabelConfigResourceKind
+
+	tests := []struct {
+		name    string
+		object  interface{}
+		desired string
+		// want is decoded from the result into a new value of its type
+		want    interface{}
+		wantErr bool
+	}{
+		{
+			name:    "v1alpha1 to v1beta1",
+			object:  alphaConfig(),
+			desired: SchemeGroupVersion.String(),
+			want:    betaConfig(),
+		},
+		{
+			name:    "v1beta1 to v1alpha1",
+			object:  betaConfig(),
+			desired: v1alpha1.SchemeGroupVersion.String(),
+			want:    alphaConfig(),
+		},
+		{
+			name:    "already v1alpha1",
+			object:  alphaConfig(),
+			desired: v1alpha1.SchemeGroupVersion.String(),
+			want:    alphaConfig(),
+		},
+		{
+			name:    "already v1beta1",
+			object:  betaConfig(),
+			desired: SchemeGroupVersion.String(),
+			want:    betaConfig(),
+		},
+		{
+			name:    "unknown version",
+			object:  alphaConfig(),
+			desired: "podlabeler.k8s.carsonoid.net/v1",
+			wantErr: true,
+		},
+		{
+			name:    "other kind",
+			object:  wrongKind,
+			desired: SchemeGroupVersion.String(),
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			converted, err := ConvertPodLabelConfig(mustMarshal(t, tt.object), tt.desired)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("expected an error, got %s", converted.Raw)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			var got interface{}
+			switch tt.want.(type) {
+			case *PodLabelConfig:
+				got = &PodLabelConfig{}
+			case *v1alpha1.PodLabelConfig:
+				got = &v1alpha1.PodLabelConfig{}
+			}
+			if err := json.Unmarshal(converted.Raw, got); err != nil {
+				t.Fatalf("decoding %s: %s", converted.Raw, err)
+			}
+			if !equality.Semantic.DeepEqual(got, tt.want) {
+				t.Errorf("ConvertPodLabelConfig() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
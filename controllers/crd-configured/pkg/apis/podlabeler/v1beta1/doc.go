@@ -0,0 +1,11 @@
+// +k8s:deepcopy-gen=package,register
+// +groupName=podlabeler.k8s.carsonoid.net
+// +groupGoName=PodLabeler
+
+/*
+Package v1beta1 implements the v1beta1 version of podlabeler resources
+
+These types serve as source file for client-go code generation. The types here also result
+in a client-go compatible client.
+*/
+package v1beta1
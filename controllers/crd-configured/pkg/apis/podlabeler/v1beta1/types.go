@@ -0,0 +1,148 @@
+package v1beta1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// -------------------------------------------------------------------------------- PodLabelConfig
+// generation tags. The empty line after is IMPORTANT!
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:resource:shortName=plc
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Priority",type=integer,JSONPath=`.spec.priority`
+// +kubebuilder:printcolumn:name="Mode",type=string,JSONPath=`.spec.mode`
+// +kubebuilder:printcolumn:name="Matched",type=integer,JSONPath=`.status.matchedPods`
+// +kubebuilder:printcolumn:name="Patched",type=integer,JSONPath=`.status.patchedPods`
+// +kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// PodLabelConfig represents a set of labels to be applied to pods in a namespace
+type PodLabelConfig struct {
+	metav1.TypeMeta `json:",inline"`
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// Spec defines the config
+	Spec PodLabelConfigSpec `json:"spec,omitempty"`
+
+	// Status describes the observed state of the config
+	// +optional
+	Status PodLabelConfigStatus `json:"status,omitempty"`
+}
+
+// PodLabelConfigSpec describes the labels and annotations to apply to the selected pods in a namespace
+type PodLabelConfigSpec struct {
+	// Labels is a map of the labels to be applied to pods in the namespace
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// Annotations is a map of the annotations to be applied to pods in the namespace
+	// +optional
+	Annotations map[string]string `json:"annotations,omitempty"`
+
+	// Selector limits the pods in the namespace that the labels are applied to.
+	// A missing selector selects every pod in the namespace. Named podSelector in v1alpha1
+	// +optional
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+
+	// Priority decides which config wins when several configs set the same key on a pod.
+	// The config with the highest priority wins. Configs with equal priority are ordered by name
+	// and the first name wins.
+	// +optional
+	Priority int32 `json:"priority,omitempty"`
+
+	// Mode decides if the config overwrites existing values, only adds missing keys or only reports
+	// the changes it would make. Defaults to Enforce
+	// +optional
+	Mode PodLabelConfigMode `json:"mode,omitempty"`
+
+	// PodTemplates also applies the labels and annotations to the pod templates of the Deployments,
+	// StatefulSets, DaemonSets and CronJobs selected by the config, so new pods are created with them.
+	// Templated label values are only applied to pods
+	// +optional
+	PodTemplates bool `json:"podTemplates,omitempty"`
+}
+
+// PodLabelConfigMode is one of Enforce, IfAbsent or Audit
+// +kubebuilder:validation:Enum=Enforce;IfAbsent;Audit
+type PodLabelConfigMode string
+
+const (
+	// PodLabelConfigModeEnforce patches pods with the labels and annotations of the config
+	PodLabelConfigModeEnforce PodLabelConfigMode = "Enforce"
+	// PodLabelConfigModeIfAbsent only sets keys a pod does not have yet. Existing values set by anything
+	// else are reported with an event but never overwritten
+	PodLabelConfigModeIfAbsent PodLabelConfigMode = "IfAbsent"
+	// PodLabelConfigModeAudit only logs and records an event for the patches the config would make
+	PodLabelConfigModeAudit PodLabelConfigMode = "Audit"
+)
+
+// PodLabelConfigConditionType is one of Ready, Conflicting or Error
+// +kubebuilder:validation:Enum=Ready;Conflicting;Error
+type PodLabelConfigConditionType string
+
+const (
+	// PodLabelConfigReady is true when the current generation of the config was applied to all matched pods
+	PodLabelConfigReady PodLabelConfigConditionType = "Ready"
+	// PodLabelConfigConflicting is true when a config with precedence sets one of the same keys to a different value
+	PodLabelConfigConflicting PodLabelConfigConditionType = "Conflicting"
+	// PodLabelConfigError is true when the config could not be applied, the message holds the last error
+	PodLabelConfigError PodLabelConfigConditionType = "Error"
+)
+
+// PodLabelConfigCondition describes the state of a config at a certain point
+type PodLabelConfigCondition struct {
+	// Type of the condition
+	Type PodLabelConfigConditionType `json:"type"`
+
+	// Status of the condition, one of True, False, Unknown
+	Status corev1.ConditionStatus `json:"status"`
+
+	// LastTransitionTime is the last time the condition changed status
+	// +optional
+	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty"`
+
+	// Reason is a one-word CamelCase reason for the condition's last transition
+	// +optional
+	Reason string `json:"reason,omitempty"`
+
+	// Message is a human readable description of the details of the last transition
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// PodLabelConfigStatus describes the observed state of a config
+type PodLabelConfigStatus struct {
+	// ObservedGeneration is the generation of the config that was last reconciled
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// MatchedPods is the number of pods selected by the config
+	// +optional
+	MatchedPods int32 `json:"matchedPods,omitempty"`
+
+	// PatchedPods is the number of selected pods that carry labels or annotations set by the config
+	// +optional
+	PatchedPods int32 `json:"patchedPods,omitempty"`
+
+	// LastReconcileTime is the last time all pods of the config were reconciled
+	// +optional
+	LastReconcileTime *metav1.Time `json:"lastReconcileTime,omitempty"`
+
+	// Conditions is the current set of conditions for the config
+	// +optional
+	Conditions []PodLabelConfigCondition `json:"conditions,omitempty"`
+}
+
+// generation tags. The empty line after is IMPORTANT!
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// PodLabelConfigList is a list of PodLabelConfigs
+type PodLabelConfigList struct {
+	metav1.TypeMeta `json:",inline"`
+	// +optional
+	metav1.ListMeta `json:"metadata"`
+
+	Items []PodLabelConfig `json:"items"`
+}
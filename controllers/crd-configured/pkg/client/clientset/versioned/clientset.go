@@ -18,6 +18,7 @@ package versioned
 
 import (
 	podlabelerv1alpha1 "github.com/carsonoid/kube-crds-and-controllers/controllers/crd-configured/pkg/client/clientset/versioned/typed/podlabeler/v1alpha1"
+	podlabelerv1beta1 "github.com/carsonoid/kube-crds-and-controllers/controllers/crd-configured/pkg/client/clientset/versioned/typed/podlabeler/v1beta1"
 	glog "github.com/golang/glog"
 	discovery "k8s.io/client-go/discovery"
 	rest "k8s.io/client-go/rest"
@@ -27,8 +28,9 @@ import (
 type Interface interface {
 	Discovery() discovery.DiscoveryInterface
 	PodlabelerV1alpha1() podlabelerv1alpha1.PodlabelerV1alpha1Interface
+	PodlabelerV1beta1() podlabelerv1beta1.PodlabelerV1beta1Interface
 	// Deprecated: please explicitly pick a version if possible.
-	Podlabeler() podlabelerv1alpha1.PodlabelerV1alpha1Interface
+	Podlabeler() podlabelerv1beta1.PodlabelerV1beta1Interface
 }
 
 // Clientset contains the clients for groups. Each group has exactly one
@@ -36,6 +38,7 @@ type Interface interface {
 type Clientset struct {
 	*discovery.DiscoveryClient
 	podlabelerV1alpha1 *podlabelerv1alpha1.PodlabelerV1alpha1Client
+	podlabelerV1beta1  *podlabelerv1beta1.PodlabelerV1beta1Client
 }
 
 // PodlabelerV1alpha1 retrieves the PodlabelerV1alpha1Client
@@ -43,10 +46,15 @@ func (c *Clientset) PodlabelerV1alpha1() podlabelerv1alpha1.PodlabelerV1alpha1In
 	return c.podlabelerV1alpha1
 }
 
+// PodlabelerV1beta1 retrieves the PodlabelerV1beta1Client
+func (c *Clientset) PodlabelerV1beta1() podlabelerv1beta1.PodlabelerV1beta1Interface {
+	return c.podlabelerV1beta1
+}
+
 // Deprecated: Podlabeler retrieves the default version of PodlabelerClient.
 // Please explicitly pick a version.
-func (c *Clientset) Podlabeler() podlabelerv1alpha1.PodlabelerV1alpha1Interface {
-	return c.podlabelerV1alpha1
+func (c *Clientset) Podlabeler() podlabelerv1beta1.PodlabelerV1beta1Interface {
+	return c.podlabelerV1beta1
 }
 
 // Discovery retrieves the DiscoveryClient
@@ -69,6 +77,10 @@ func NewForConfig(c *rest.Config) (*Clientset, error) {
 	if err != nil {
 		return nil, err
 	}
+	cs.podlabelerV1beta1, err = podlabelerv1beta1.NewForConfig(&configShallowCopy)
+	if err != nil {
+		return nil, err
+	}
 
 	cs.DiscoveryClient, err = discovery.NewDiscoveryClientForConfig(&configShallowCopy)
 	if err != nil {
@@ -83,6 +95,7 @@ func NewForConfig(c *rest.Config) (*Clientset, error) {
 func NewForConfigOrDie(c *rest.Config) *Clientset {
 	var cs Clientset
 	cs.podlabelerV1alpha1 = podlabelerv1alpha1.NewForConfigOrDie(c)
+	cs.podlabelerV1beta1 = podlabelerv1beta1.NewForConfigOrDie(c)
 
 	cs.DiscoveryClient = discovery.NewDiscoveryClientForConfigOrDie(c)
 	return &cs
@@ -92,6 +105,7 @@ func NewForConfigOrDie(c *rest.Config) *Clientset {
 func New(c rest.Interface) *Clientset {
 	var cs Clientset
 	cs.podlabelerV1alpha1 = podlabelerv1alpha1.New(c)
+	cs.podlabelerV1beta1 = podlabelerv1beta1.New(c)
 
 	cs.DiscoveryClient = discovery.NewDiscoveryClient(c)
 	return &cs
@@ -20,6 +20,8 @@ import (
 	clientset "github.com/carsonoid/kube-crds-and-controllers/controllers/crd-configured/pkg/client/clientset/versioned"
 	podlabelerv1alpha1 "github.com/carsonoid/kube-crds-and-controllers/controllers/crd-configured/pkg/client/clientset/versioned/typed/podlabeler/v1alpha1"
 	fakepodlabelerv1alpha1 "github.com/carsonoid/kube-crds-and-controllers/controllers/crd-configured/pkg/client/clientset/versioned/typed/podlabeler/v1alpha1/fake"
+	podlabelerv1beta1 "github.com/carsonoid/kube-crds-and-controllers/controllers/crd-configured/pkg/client/clientset/versioned/typed/podlabeler/v1beta1"
+	fakepodlabelerv1beta1 "github.com/carsonoid/kube-crds-and-controllers/controllers/crd-configured/pkg/client/clientset/versioned/typed/podlabeler/v1beta1/fake"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/discovery"
@@ -65,7 +67,12 @@ func (c *Clientset) PodlabelerV1alpha1() podlabelerv1alpha1.PodlabelerV1alpha1In
 	return &fakepodlabelerv1alpha1.FakePodlabelerV1alpha1{Fake: &c.Fake}
 }
 
-// Podlabeler retrieves the PodlabelerV1alpha1Client
-func (c *Clientset) Podlabeler() podlabelerv1alpha1.PodlabelerV1alpha1Interface {
-	return &fakepodlabelerv1alpha1.FakePodlabelerV1alpha1{Fake: &c.Fake}
+// PodlabelerV1beta1 retrieves the PodlabelerV1beta1Client
+func (c *Clientset) PodlabelerV1beta1() podlabelerv1beta1.PodlabelerV1beta1Interface {
+	return &fakepodlabelerv1beta1.FakePodlabelerV1beta1{Fake: &c.Fake}
+}
+
+// Podlabeler retrieves the PodlabelerV1beta1Client
+func (c *Clientset) Podlabeler() podlabelerv1beta1.PodlabelerV1beta1Interface {
+	return &fakepodlabelerv1beta1.FakePodlabelerV1beta1{Fake: &c.Fake}
 }
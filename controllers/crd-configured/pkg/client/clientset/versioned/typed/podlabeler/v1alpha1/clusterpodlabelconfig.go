@@ -0,0 +1,145 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	v1alpha1 "github.com/carsonoid/kube-crds-and-controllers/controllers/crd-configured/pkg/apis/podlabeler/v1alpha1"
+	scheme "github.com/carsonoid/kube-crds-and-controllers/controllers/crd-configured/pkg/client/clientset/versioned/scheme"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	rest "k8s.io/client-go/rest"
+)
+
+// ClusterPodLabelConfigsGetter has a method to return a ClusterPodLabelConfigInterface.
+// A group's client should implement this interface.
+type ClusterPodLabelConfigsGetter interface {
+	ClusterPodLabelConfigs() ClusterPodLabelConfigInterface
+}
+
+// ClusterPodLabelConfigInterface has methods to work with ClusterPodLabelConfig resources.
+type ClusterPodLabelConfigInterface interface {
+	Create(*v1alpha1.ClusterPodLabelConfig) (*v1alpha1.ClusterPodLabelConfig, error)
+	Update(*v1alpha1.ClusterPodLabelConfig) (*v1alpha1.ClusterPodLabelConfig, error)
+	Delete(name string, options *v1.DeleteOptions) error
+	DeleteCollection(options *v1.DeleteOptions, listOptions v1.ListOptions) error
+	Get(name string, options v1.GetOptions) (*v1alpha1.ClusterPodLabelConfig, error)
+	List(opts v1.ListOptions) (*v1alpha1.ClusterPodLabelConfigList, error)
+	Watch(opts v1.ListOptions) (watch.Interface, error)
+	Patch(name string, pt types.PatchType, data []byte, subresources ...string) (result *v1alpha1.ClusterPodLabelConfig, err error)
+	ClusterPodLabelConfigExpansion
+}
+
+// clusterPodLabelConfigs implements ClusterPodLabelConfigInterface
+type clusterPodLabelConfigs struct {
+	client rest.Interface
+}
+
+// newClusterPodLabelConfigs returns a ClusterPodLabelConfigs
+func newClusterPodLabelConfigs(c *PodlabelerV1alpha1Client) *clusterPodLabelConfigs {
+	return &clusterPodLabelConfigs{
+		client: c.RESTClient(),
+	}
+}
+
+// Get takes name of the clusterPodLabelConfig, and returns the corresponding clusterPodLabelConfig object, and an error if there is any.
+func (c *clusterPodLabelConfigs) Get(name string, options v1.GetOptions) (result *v1alpha1.ClusterPodLabelConfig, err error) {
+	result = &v1alpha1.ClusterPodLabelConfig{}
+	err = c.client.Get().
+		Resource("clusterpodlabelconfigs").
+		Name(name).
+		VersionedParams(&options, scheme.ParameterCodec).
+		Do().
+		Into(result)
+	return
+}
+
+// List takes label and field selectors, and returns the list of ClusterPodLabelConfigs that match those selectors.
+func (c *clusterPodLabelConfigs) List(opts v1.ListOptions) (result *v1alpha1.ClusterPodLabelConfigList, err error) {
+	result = &v1alpha1.ClusterPodLabelConfigList{}
+	err = c.client.Get().
+		Resource("clusterpodlabelconfigs").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Do().
+		Into(result)
+	return
+}
+
+// Watch returns a watch.Interface that watches the requested clusterPodLabelConfigs.
+func (c *clusterPodLabelConfigs) Watch(opts v1.ListOptions) (watch.Interface, error) {
+	opts.Watch = true
+	return c.client.Get().
+		Resource("clusterpodlabelconfigs").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Watch()
+}
+
+// Create takes the representation of a clusterPodLabelConfig and creates it.  Returns the server's representation of the clusterPodLabelConfig, and an error, if there is any.
+func (c *clusterPodLabelConfigs) Create(clusterPodLabelConfig *v1alpha1.ClusterPodLabelConfig) (result *v1alpha1.ClusterPodLabelConfig, err error) {
+	result = &v1alpha1.ClusterPodLabelConfig{}
+	err = c.client.Post().
+		Resource("clusterpodlabelconfigs").
+		Body(clusterPodLabelConfig).
+		Do().
+		Into(result)
+	return
+}
+
+// Update takes the representation of a clusterPodLabelConfig and updates it. Returns the server's representation of the clusterPodLabelConfig, and an error, if there is any.
+func (c *clusterPodLabelConfigs) Update(clusterPodLabelConfig *v1alpha1.ClusterPodLabelConfig) (result *v1alpha1.ClusterPodLabelConfig, err error) {
+	result = &v1alpha1.ClusterPodLabelConfig{}
+	err = c.client.Put().
+		Resource("clusterpodlabelconfigs").
+		Name(clusterPodLabelConfig.Name).
+		Body(clusterPodLabelConfig).
+		Do().
+		Into(result)
+	return
+}
+
+// Delete takes name of the clusterPodLabelConfig and deletes it. Returns an error if one occurs.
+func (c *clusterPodLabelConfigs) Delete(name string, options *v1.DeleteOptions) error {
+	return c.client.Delete().
+		Resource("clusterpodlabelconfigs").
+		Name(name).
+		Body(options).
+		Do().
+		Error()
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *clusterPodLabelConfigs) DeleteCollection(options *v1.DeleteOptions, listOptions v1.ListOptions) error {
+	return c.client.Delete().
+		Resource("clusterpodlabelconfigs").
+		VersionedParams(&listOptions, scheme.ParameterCodec).
+		Body(options).
+		Do().
+		Error()
+}
+
+// Patch applies the patch and returns the patched clusterPodLabelConfig.
+func (c *clusterPodLabelConfigs) Patch(name string, pt types.PatchType, data []byte, subresources ...string) (result *v1alpha1.ClusterPodLabelConfig, err error) {
+	result = &v1alpha1.ClusterPodLabelConfig{}
+	err = c.client.Patch(pt).
+		Resource("clusterpodlabelconfigs").
+		SubResource(subresources...).
+		Name(name).
+		Body(data).
+		Do().
+		Into(result)
+	return
+}
@@ -0,0 +1,125 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fake
+
+import (
+	v1alpha1 "github.com/carsonoid/kube-crds-and-controllers/controllers/crd-configured/pkg/apis/podlabeler/v1alpha1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	labels "k8s.io/apimachinery/pkg/labels"
+	schema "k8s.io/apimachinery/pkg/runtime/schema"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	testing "k8s.io/client-go/testing"
+)
+
+// FakeClusterPodLabelConfigs implements ClusterPodLabelConfigInterface
+type FakeClusterPodLabelConfigs struct {
+	Fake *FakePodlabelerV1alpha1
+}
+
+var clusterpodlabelconfigsResource = schema.GroupVersionResource{Group: "podlabeler.k8s.carsonoid.net", Version: "v1alpha1", Resource: "clusterpodlabelconfigs"}
+
+var clusterpodlabelconfigsKind = schema.GroupVersionKind{Group: "podlabeler.k8s.carsonoid.net", Version: "v1alpha1", Kind: "ClusterPodLabelConfig"}
+
+// Get takes name of the clusterPodLabelConfig, and returns the corresponding clusterPodLabelConfig object, and an error if there is any.
+func (c *FakeClusterPodLabelConfigs) Get(name string, options v1.GetOptions) (result *v1alpha1.ClusterPodLabelConfig, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewRootGetAction(clusterpodlabelconfigsResource, name), &v1alpha1.ClusterPodLabelConfig{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.ClusterPodLabelConfig), err
+}
+
+// List takes label and field selectors, and returns the list of ClusterPodLabelConfigs that match those selectors.
+func (c *FakeClusterPodLabelConfigs) List(opts v1.ListOptions) (result *v1alpha1.ClusterPodLabelConfigList, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewRootListAction(clusterpodlabelconfigsResource, clusterpodlabelconfigsKind, opts), &v1alpha1.ClusterPodLabelConfigList{})
+
+	if obj == nil {
+		return nil, err
+	}
+
+	label, _, _ := testing.ExtractFromListOptions(opts)
+	if label == nil {
+		label = labels.Everything()
+	}
+	list := &v1alpha1.ClusterPodLabelConfigList{}
+	for _, item := range obj.(*v1alpha1.ClusterPodLabelConfigList).Items {
+		if label.Matches(labels.Set(item.Labels)) {
+			list.Items = append(list.Items, item)
+		}
+	}
+	return list, err
+}
+
+// Watch returns a watch.Interface that watches the requested clusterPodLabelConfigs.
+func (c *FakeClusterPodLabelConfigs) Watch(opts v1.ListOptions) (watch.Interface, error) {
+	return c.Fake.
+		InvokesWatch(testing.NewRootWatchAction(clusterpodlabelconfigsResource, opts))
+
+}
+
+// Create takes the representation of a clusterPodLabelConfig and creates it.  Returns the server's representation of the clusterPodLabelConfig, and an error, if there is any.
+func (c *FakeClusterPodLabelConfigs) Create(clusterPodLabelConfig *v1alpha1.ClusterPodLabelConfig) (result *v1alpha1.ClusterPodLabelConfig, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewRootCreateAction(clusterpodlabelconfigsResource, clusterPodLabelConfig), &v1alpha1.ClusterPodLabelConfig{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.ClusterPodLabelConfig), err
+}
+
+// Update takes the representation of a clusterPodLabelConfig and updates it. Returns the server's representation of the clusterPodLabelConfig, and an error, if there is any.
+func (c *FakeClusterPodLabelConfigs) Update(clusterPodLabelConfig *v1alpha1.ClusterPodLabelConfig) (result *v1alpha1.ClusterPodLabelConfig, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewRootUpdateAction(clusterpodlabelconfigsResource, clusterPodLabelConfig), &v1alpha1.ClusterPodLabelConfig{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.ClusterPodLabelConfig), err
+}
+
+// Delete takes name of the clusterPodLabelConfig and deletes it. Returns an error if one occurs.
+func (c *FakeClusterPodLabelConfigs) Delete(name string, options *v1.DeleteOptions) error {
+	_, err := c.Fake.
+		Invokes(testing.NewRootDeleteAction(clusterpodlabelconfigsResource, name), &v1alpha1.ClusterPodLabelConfig{})
+
+	return err
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *FakeClusterPodLabelConfigs) DeleteCollection(options *v1.DeleteOptions, listOptions v1.ListOptions) error {
+	action := testing.NewRootDeleteCollectionAction(clusterpodlabelconfigsResource, listOptions)
+
+	_, err := c.Fake.Invokes(action, &v1alpha1.ClusterPodLabelConfigList{})
+	return err
+}
+
+// Patch applies the patch and returns the patched clusterPodLabelConfig.
+func (c *FakeClusterPodLabelConfigs) Patch(name string, pt types.PatchType, data []byte, subresources ...string) (result *v1alpha1.ClusterPodLabelConfig, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewRootPatchSubresourceAction(clusterpodlabelconfigsResource, name, data, subresources...), &v1alpha1.ClusterPodLabelConfig{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.ClusterPodLabelConfig), err
+}
@@ -98,6 +98,18 @@ func (c *FakePodLabelConfigs) Update(podLabelConfig *v1alpha1.PodLabelConfig) (r
 	return obj.(*v1alpha1.PodLabelConfig), err
 }
 
+// UpdateStatus was generated because the type contains a Status member.
+// Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
+func (c *FakePodLabelConfigs) UpdateStatus(podLabelConfig *v1alpha1.PodLabelConfig) (*v1alpha1.PodLabelConfig, error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewUpdateSubresourceAction(podlabelconfigsResource, "status", c.ns, podLabelConfig), &v1alpha1.PodLabelConfig{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.PodLabelConfig), err
+}
+
 // Delete takes name of the podLabelConfig and deletes it. Returns an error if one occurs.
 func (c *FakePodLabelConfigs) Delete(name string, options *v1.DeleteOptions) error {
 	_, err := c.Fake.
@@ -26,10 +26,18 @@ type FakePodlabelerV1alpha1 struct {
 	*testing.Fake
 }
 
+func (c *FakePodlabelerV1alpha1) ClusterPodLabelConfigs() v1alpha1.ClusterPodLabelConfigInterface {
+	return &FakeClusterPodLabelConfigs{c}
+}
+
 func (c *FakePodlabelerV1alpha1) PodLabelConfigs(namespace string) v1alpha1.PodLabelConfigInterface {
 	return &FakePodLabelConfigs{c, namespace}
 }
 
+func (c *FakePodlabelerV1alpha1) ResourceLabelConfigs() v1alpha1.ResourceLabelConfigInterface {
+	return &FakeResourceLabelConfigs{c}
+}
+
 // RESTClient returns a RESTClient that is used to communicate
 // with API server by this client implementation.
 func (c *FakePodlabelerV1alpha1) RESTClient() rest.Interface {
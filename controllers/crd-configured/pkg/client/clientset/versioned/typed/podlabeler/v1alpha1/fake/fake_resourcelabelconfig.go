@@ -0,0 +1,125 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fake
+
+import (
+	v1alpha1 "github.com/carsonoid/kube-crds-and-controllers/controllers/crd-configured/pkg/apis/podlabeler/v1alpha1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	labels "k8s.io/apimachinery/pkg/labels"
+	schema "k8s.io/apimachinery/pkg/runtime/schema"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	testing "k8s.io/client-go/testing"
+)
+
+// FakeResourceLabelConfigs implements ResourceLabelConfigInterface
+type FakeResourceLabelConfigs struct {
+	Fake *FakePodlabelerV1alpha1
+}
+
+var resourcelabelconfigsResource = schema.GroupVersionResource{Group: "podlabeler.k8s.carsonoid.net", Version: "v1alpha1", Resource: "resourcelabelconfigs"}
+
+var resourcelabelconfigsKind = schema.GroupVersionKind{Group: "podlabeler.k8s.carsonoid.net", Version: "v1alpha1", Kind: "ResourceLabelConfig"}
+
+// Get takes name of the resourceLabelConfig, and returns the corresponding resourceLabelConfig object, and an error if there is any.
+func (c *FakeResourceLabelConfigs) Get(name string, options v1.GetOptions) (result *v1alpha1.ResourceLabelConfig, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewRootGetAction(resourcelabelconfigsResource, name), &v1alpha1.ResourceLabelConfig{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.ResourceLabelConfig), err
+}
+
+// List takes label and field selectors, and returns the list of ResourceLabelConfigs that match those selectors.
+func (c *FakeResourceLabelConfigs) List(opts v1.ListOptions) (result *v1alpha1.ResourceLabelConfigList, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewRootListAction(resourcelabelconfigsResource, resourcelabelconfigsKind, opts), &v1alpha1.ResourceLabelConfigList{})
+
+	if obj == nil {
+		return nil, err
+	}
+
+	label, _, _ := testing.ExtractFromListOptions(opts)
+	if label == nil {
+		label = labels.Everything()
+	}
+	list := &v1alpha1.ResourceLabelConfigList{}
+	for _, item := range obj.(*v1alpha1.ResourceLabelConfigList).Items {
+		if label.Matches(labels.Set(item.Labels)) {
+			list.Items = append(list.Items, item)
+		}
+	}
+	return list, err
+}
+
+// Watch returns a watch.Interface that watches the requested resourceLabelConfigs.
+func (c *FakeResourceLabelConfigs) Watch(opts v1.ListOptions) (watch.Interface, error) {
+	return c.Fake.
+		InvokesWatch(testing.NewRootWatchAction(resourcelabelconfigsResource, opts))
+
+}
+
+// Create takes the representation of a resourceLabelConfig and creates it.  Returns the server's representation of the resourceLabelConfig, and an error, if there is any.
+func (c *FakeResourceLabelConfigs) Create(resourceLabelConfig *v1alpha1.ResourceLabelConfig) (result *v1alpha1.ResourceLabelConfig, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewRootCreateAction(resourcelabelconfigsResource, resourceLabelConfig), &v1alpha1.ResourceLabelConfig{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.ResourceLabelConfig), err
+}
+
+// Update takes the representation of a resourceLabelConfig and updates it. Returns the server's representation of the resourceLabelConfig, and an error, if there is any.
+func (c *FakeResourceLabelConfigs) Update(resourceLabelConfig *v1alpha1.ResourceLabelConfig) (result *v1alpha1.ResourceLabelConfig, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewRootUpdateAction(resourcelabelconfigsResource, resourceLabelConfig), &v1alpha1.ResourceLabelConfig{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.ResourceLabelConfig), err
+}
+
+// Delete takes name of the resourceLabelConfig and deletes it. Returns an error if one occurs.
+func (c *FakeResourceLabelConfigs) Delete(name string, options *v1.DeleteOptions) error {
+	_, err := c.Fake.
+		Invokes(testing.NewRootDeleteAction(resourcelabelconfigsResource, name), &v1alpha1.ResourceLabelConfig{})
+
+	return err
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *FakeResourceLabelConfigs) DeleteCollection(options *v1.DeleteOptions, listOptions v1.ListOptions) error {
+	action := testing.NewRootDeleteCollectionAction(resourcelabelconfigsResource, listOptions)
+
+	_, err := c.Fake.Invokes(action, &v1alpha1.ResourceLabelConfigList{})
+	return err
+}
+
+// Patch applies the patch and returns the patched resourceLabelConfig.
+func (c *FakeResourceLabelConfigs) Patch(name string, pt types.PatchType, data []byte, subresources ...string) (result *v1alpha1.ResourceLabelConfig, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewRootPatchSubresourceAction(resourcelabelconfigsResource, name, data, subresources...), &v1alpha1.ResourceLabelConfig{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.ResourceLabelConfig), err
+}
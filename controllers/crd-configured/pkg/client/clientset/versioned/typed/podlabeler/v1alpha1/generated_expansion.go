@@ -16,4 +16,8 @@ limitations under the License.
 
 package v1alpha1
 
+type ClusterPodLabelConfigExpansion interface{}
+
 type PodLabelConfigExpansion interface{}
+
+type ResourceLabelConfigExpansion interface{}
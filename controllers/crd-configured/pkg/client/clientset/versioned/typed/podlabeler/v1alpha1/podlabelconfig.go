@@ -35,6 +35,7 @@ type PodLabelConfigsGetter interface {
 type PodLabelConfigInterface interface {
 	Create(*v1alpha1.PodLabelConfig) (*v1alpha1.PodLabelConfig, error)
 	Update(*v1alpha1.PodLabelConfig) (*v1alpha1.PodLabelConfig, error)
+	UpdateStatus(*v1alpha1.PodLabelConfig) (*v1alpha1.PodLabelConfig, error)
 	Delete(name string, options *v1.DeleteOptions) error
 	DeleteCollection(options *v1.DeleteOptions, listOptions v1.ListOptions) error
 	Get(name string, options v1.GetOptions) (*v1alpha1.PodLabelConfig, error)
@@ -118,6 +119,22 @@ func (c *podLabelConfigs) Update(podLabelConfig *v1alpha1.PodLabelConfig) (resul
 	return
 }
 
+// UpdateStatus was generated because the type contains a Status member.
+// Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
+
+func (c *podLabelConfigs) UpdateStatus(podLabelConfig *v1alpha1.PodLabelConfig) (result *v1alpha1.PodLabelConfig, err error) {
+	result = &v1alpha1.PodLabelConfig{}
+	err = c.client.Put().
+		Namespace(c.ns).
+		Resource("podlabelconfigs").
+		Name(podLabelConfig.Name).
+		SubResource("status").
+		Body(podLabelConfig).
+		Do().
+		Into(result)
+	return
+}
+
 // Delete takes name of the podLabelConfig and deletes it. Returns an error if one occurs.
 func (c *podLabelConfigs) Delete(name string, options *v1.DeleteOptions) error {
 	return c.client.Delete().
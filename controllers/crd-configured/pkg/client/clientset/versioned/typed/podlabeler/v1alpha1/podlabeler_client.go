@@ -25,7 +25,9 @@ import (
 
 type PodlabelerV1alpha1Interface interface {
 	RESTClient() rest.Interface
+	ClusterPodLabelConfigsGetter
 	PodLabelConfigsGetter
+	ResourceLabelConfigsGetter
 }
 
 // PodlabelerV1alpha1Client is used to interact with features provided by the podlabeler.k8s.carsonoid.net group.
@@ -33,10 +35,18 @@ type PodlabelerV1alpha1Client struct {
 	restClient rest.Interface
 }
 
+func (c *PodlabelerV1alpha1Client) ClusterPodLabelConfigs() ClusterPodLabelConfigInterface {
+	return newClusterPodLabelConfigs(c)
+}
+
 func (c *PodlabelerV1alpha1Client) PodLabelConfigs(namespace string) PodLabelConfigInterface {
 	return newPodLabelConfigs(c, namespace)
 }
 
+func (c *PodlabelerV1alpha1Client) ResourceLabelConfigs() ResourceLabelConfigInterface {
+	return newResourceLabelConfigs(c)
+}
+
 // NewForConfig creates a new PodlabelerV1alpha1Client for the given config.
 func NewForConfig(c *rest.Config) (*PodlabelerV1alpha1Client, error) {
 	config := *c
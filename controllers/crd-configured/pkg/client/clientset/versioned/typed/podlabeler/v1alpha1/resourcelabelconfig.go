@@ -0,0 +1,145 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	v1alpha1 "github.com/carsonoid/kube-crds-and-controllers/controllers/crd-configured/pkg/apis/podlabeler/v1alpha1"
+	scheme "github.com/carsonoid/kube-crds-and-controllers/controllers/crd-configured/pkg/client/clientset/versioned/scheme"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	rest "k8s.io/client-go/rest"
+)
+
+// ResourceLabelConfigsGetter has a method to return a ResourceLabelConfigInterface.
+// A group's client should implement this interface.
+type ResourceLabelConfigsGetter interface {
+	ResourceLabelConfigs() ResourceLabelConfigInterface
+}
+
+// ResourceLabelConfigInterface has methods to work with ResourceLabelConfig resources.
+type ResourceLabelConfigInterface interface {
+	Create(*v1alpha1.ResourceLabelConfig) (*v1alpha1.ResourceLabelConfig, error)
+	Update(*v1alpha1.ResourceLabelConfig) (*v1alpha1.ResourceLabelConfig, error)
+	Delete(name string, options *v1.DeleteOptions) error
+	DeleteCollection(options *v1.DeleteOptions, listOptions v1.ListOptions) error
+	Get(name string, options v1.GetOptions) (*v1alpha1.ResourceLabelConfig, error)
+	List(opts v1.ListOptions) (*v1alpha1.ResourceLabelConfigList, error)
+	Watch(opts v1.ListOptions) (watch.Interface, error)
+	Patch(name string, pt types.PatchType, data []byte, subresources ...string) (result *v1alpha1.ResourceLabelConfig, err error)
+	ResourceLabelConfigExpansion
+}
+
+// resourceLabelConfigs implements ResourceLabelConfigInterface
+type resourceLabelConfigs struct {
+	client rest.Interface
+}
+
+// newResourceLabelConfigs returns a ResourceLabelConfigs
+func newResourceLabelConfigs(c *PodlabelerV1alpha1Client) *resourceLabelConfigs {
+	return &resourceLabelConfigs{
+		client: c.RESTClient(),
+	}
+}
+
+// Get takes name of the resourceLabelConfig, and returns the corresponding resourceLabelConfig object, and an error if there is any.
+func (c *resourceLabelConfigs) Get(name string, options v1.GetOptions) (result *v1alpha1.ResourceLabelConfig, err error) {
+	result = &v1alpha1.ResourceLabelConfig{}
+	err = c.client.Get().
+		Resource("resourcelabelconfigs").
+		Name(name).
+		VersionedParams(&options, scheme.ParameterCodec).
+		Do().
+		Into(result)
+	return
+}
+
+// List takes label and field selectors, and returns the list of ResourceLabelConfigs that match those selectors.
+func (c *resourceLabelConfigs) List(opts v1.ListOptions) (result *v1alpha1.ResourceLabelConfigList, err error) {
+	result = &v1alpha1.ResourceLabelConfigList{}
+	err = c.client.Get().
+		Resource("resourcelabelconfigs").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Do().
+		Into(result)
+	return
+}
+
+// Watch returns a watch.Interface that watches the requested resourceLabelConfigs.
+func (c *resourceLabelConfigs) Watch(opts v1.ListOptions) (watch.Interface, error) {
+	opts.Watch = true
+	return c.client.Get().
+		Resource("resourcelabelconfigs").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Watch()
+}
+
+// Create takes the representation of a resourceLabelConfig and creates it.  Returns the server's representation of the resourceLabelConfig, and an error, if there is any.
+func (c *resourceLabelConfigs) Create(resourceLabelConfig *v1alpha1.ResourceLabelConfig) (result *v1alpha1.ResourceLabelConfig, err error) {
+	result = &v1alpha1.ResourceLabelConfig{}
+	err = c.client.Post().
+		Resource("resourcelabelconfigs").
+		Body(resourceLabelConfig).
+		Do().
+		Into(result)
+	return
+}
+
+// Update takes the representation of a resourceLabelConfig and updates it. Returns the server's representation of the resourceLabelConfig, and an error, if there is any.
+func (c *resourceLabelConfigs) Update(resourceLabelConfig *v1alpha1.ResourceLabelConfig) (result *v1alpha1.ResourceLabelConfig, err error) {
+	result = &v1alpha1.ResourceLabelConfig{}
+	err = c.client.Put().
+		Resource("resourcelabelconfigs").
+		Name(resourceLabelConfig.Name).
+		Body(resourceLabelConfig).
+		Do().
+		Into(result)
+	return
+}
+
+// Delete takes name of the resourceLabelConfig and deletes it. Returns an error if one occurs.
+func (c *resourceLabelConfigs) Delete(name string, options *v1.DeleteOptions) error {
+	return c.client.Delete().
+		Resource("resourcelabelconfigs").
+		Name(name).
+		Body(options).
+		Do().
+		Error()
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *resourceLabelConfigs) DeleteCollection(options *v1.DeleteOptions, listOptions v1.ListOptions) error {
+	return c.client.Delete().
+		Resource("resourcelabelconfigs").
+		VersionedParams(&listOptions, scheme.ParameterCodec).
+		Body(options).
+		Do().
+		Error()
+}
+
+// Patch applies the patch and returns the patched resourceLabelConfig.
+func (c *resourceLabelConfigs) Patch(name string, pt types.PatchType, data []byte, subresources ...string) (result *v1alpha1.ResourceLabelConfig, err error) {
+	result = &v1alpha1.ResourceLabelConfig{}
+	err = c.client.Patch(pt).
+		Resource("resourcelabelconfigs").
+		SubResource(subresources...).
+		Name(name).
+		Body(data).
+		Do().
+		Into(result)
+	return
+}
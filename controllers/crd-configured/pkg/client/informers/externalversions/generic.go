@@ -21,6 +21,7 @@ package externalversions
 import (
 	"fmt"
 	v1alpha1 "github.com/carsonoid/kube-crds-and-controllers/controllers/crd-configured/pkg/apis/podlabeler/v1alpha1"
+	v1beta1 "github.com/carsonoid/kube-crds-and-controllers/controllers/crd-configured/pkg/apis/podlabeler/v1beta1"
 	schema "k8s.io/apimachinery/pkg/runtime/schema"
 	cache "k8s.io/client-go/tools/cache"
 )
@@ -52,8 +53,16 @@ func (f *genericInformer) Lister() cache.GenericLister {
 func (f *sharedInformerFactory) ForResource(resource schema.GroupVersionResource) (GenericInformer, error) {
 	switch resource {
 	// Group=Podlabeler, Version=V1alpha1
+	case v1alpha1.SchemeGroupVersion.WithResource("clusterpodlabelconfigs"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Podlabeler().V1alpha1().ClusterPodLabelConfigs().Informer()}, nil
 	case v1alpha1.SchemeGroupVersion.WithResource("podlabelconfigs"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Podlabeler().V1alpha1().PodLabelConfigs().Informer()}, nil
+	case v1alpha1.SchemeGroupVersion.WithResource("resourcelabelconfigs"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Podlabeler().V1alpha1().ResourceLabelConfigs().Informer()}, nil
+
+		// Group=Podlabeler, Version=V1beta1
+	case v1beta1.SchemeGroupVersion.WithResource("podlabelconfigs"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Podlabeler().V1beta1().PodLabelConfigs().Informer()}, nil
 
 	}
 
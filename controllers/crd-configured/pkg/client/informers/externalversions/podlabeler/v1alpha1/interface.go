@@ -24,8 +24,12 @@ import (
 
 // Interface provides access to all the informers in this group version.
 type Interface interface {
+	// ClusterPodLabelConfigs returns a ClusterPodLabelConfigInformer.
+	ClusterPodLabelConfigs() ClusterPodLabelConfigInformer
 	// PodLabelConfigs returns a PodLabelConfigInformer.
 	PodLabelConfigs() PodLabelConfigInformer
+	// ResourceLabelConfigs returns a ResourceLabelConfigInformer.
+	ResourceLabelConfigs() ResourceLabelConfigInformer
 }
 
 type version struct {
@@ -37,7 +41,17 @@ func New(f internalinterfaces.SharedInformerFactory) Interface {
 	return &version{f}
 }
 
+// ClusterPodLabelConfigs returns a ClusterPodLabelConfigInformer.
+func (v *version) ClusterPodLabelConfigs() ClusterPodLabelConfigInformer {
+	return &clusterPodLabelConfigInformer{factory: v.SharedInformerFactory}
+}
+
 // PodLabelConfigs returns a PodLabelConfigInformer.
 func (v *version) PodLabelConfigs() PodLabelConfigInformer {
 	return &podLabelConfigInformer{factory: v.SharedInformerFactory}
 }
+
+// ResourceLabelConfigs returns a ResourceLabelConfigInformer.
+func (v *version) ResourceLabelConfigs() ResourceLabelConfigInformer {
+	return &resourceLabelConfigInformer{factory: v.SharedInformerFactory}
+}
@@ -18,6 +18,10 @@ limitations under the License.
 
 package v1alpha1
 
+// ClusterPodLabelConfigListerExpansion allows custom methods to be added to
+// ClusterPodLabelConfigLister.
+type ClusterPodLabelConfigListerExpansion interface{}
+
 // PodLabelConfigListerExpansion allows custom methods to be added to
 // PodLabelConfigLister.
 type PodLabelConfigListerExpansion interface{}
@@ -25,3 +29,7 @@ type PodLabelConfigListerExpansion interface{}
 // PodLabelConfigNamespaceListerExpansion allows custom methods to be added to
 // PodLabelConfigNamespaceLister.
 type PodLabelConfigNamespaceListerExpansion interface{}
+
+// ResourceLabelConfigListerExpansion allows custom methods to be added to
+// ResourceLabelConfigLister.
+type ResourceLabelConfigListerExpansion interface{}
@@ -0,0 +1,178 @@
+// Package clusterconfigs runs the controller for ClusterPodLabelConfigs.
+//
+// Changes to a config queue the pods, workloads and PodLabelConfigs of every namespace it selects, they are
+// labelled and checked for conflicts by their own controllers. The finalizer of a deleted config is only removed
+// once no pod or workload has keys set by it anymore.
+package clusterconfigs
+
+import (
+	"log"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+
+	"github.com/carsonoid/kube-crds-and-controllers/controllers/crd-configured/pkg/apis/podlabeler/v1alpha1"
+	pllisters "github.com/carsonoid/kube-crds-and-controllers/controllers/crd-configured/pkg/client/listers/podlabeler/v1alpha1"
+	"github.com/carsonoid/kube-crds-and-controllers/controllers/crd-configured/pkg/finalizers"
+	"github.com/carsonoid/kube-crds-and-controllers/controllers/crd-configured/pkg/labeling"
+	"github.com/carsonoid/kube-crds-and-controllers/controllers/crd-configured/pkg/selectors"
+)
+
+// Controller writes the finalizers of ClusterPodLabelConfigs and queues everything they select
+type Controller struct {
+	Lister          pllisters.ClusterPodLabelConfigLister
+	Informer        cache.SharedIndexInformer
+	NamespaceLister corelisters.NamespaceLister
+
+	// Configs adds and removes the finalizer of the configs
+	Configs finalizers.Kind
+
+	// HasSynced reports if the initial sync is complete. Nothing is reconciled or finalized before it
+	HasSynced func() bool
+	// EnqueueNamespace queues the pods and workloads of a namespace matching selector, and its PodLabelConfigs
+	EnqueueNamespace func(namespace string, selector labels.Selector)
+	// EnqueueOwned queues the pods and workloads that still have keys set by owner and returns how many were found
+	EnqueueOwned func(namespace, owner string) int
+
+	// Queue holds the names of the configs to sync. It is created by Start
+	Queue workqueue.RateLimitingInterface
+}
+
+// Start creates the queue and registers the ClusterPodLabelConfig event handlers on the shared informer.
+// The handlers only queue pods and configs, the finalizer is left to the worker syncing the queue.
+// The informer itself is started with the other informers of its factory.
+func (ctrl *Controller) Start() {
+	log.Print("Starting ClusterPodLabelConfig Controller")
+
+	ctrl.Queue = workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "clusterConfigQueue")
+
+	// The informer resyncs so that failed finalizations are retried
+	ctrl.Informer.AddEventHandler(
+		cache.ResourceEventHandlerFuncs{
+			AddFunc: func(obj interface{}) {
+				log.Print("ClusterPodLabelConfig Add Event")
+				c := obj.(*v1alpha1.ClusterPodLabelConfig)
+				ctrl.Queue.Add(c.GetName())
+				if c.GetDeletionTimestamp() != nil {
+					return
+				}
+				ctrl.ReconcileAllPods(c)
+			},
+			UpdateFunc: func(oldobj interface{}, newobj interface{}) {
+				log.Print("ClusterPodLabelConfig Update Event")
+				oldConfig := oldobj.(*v1alpha1.ClusterPodLabelConfig)
+				newConfig := newobj.(*v1alpha1.ClusterPodLabelConfig)
+
+				// Configs set for deletion get their keys removed from all pods by the finalizer
+				if newConfig.GetDeletionTimestamp() != nil {
+					ctrl.Queue.Add(newConfig.GetName())
+					return
+				}
+
+				if !finalizers.Has(newConfig, ctrl.Configs.Finalizer) {
+					ctrl.Queue.Add(newConfig.GetName())
+				}
+
+				// Make sure the spec was actually changed. Metadata updates do not affect pods
+				if !equality.Semantic.DeepEqual(oldConfig.Spec, newConfig.Spec) {
+					ctrl.ReconcileAllPods(newConfig)
+
+					// Pods or namespaces which are no longer selected need their keys removed
+					if !equality.Semantic.DeepEqual(oldConfig.Spec.PodSelector, newConfig.Spec.PodSelector) ||
+						!equality.Semantic.DeepEqual(oldConfig.Spec.NamespaceSelector, newConfig.Spec.NamespaceSelector) {
+						ctrl.ReconcileAllPods(oldConfig)
+					}
+				}
+			},
+			DeleteFunc: func(obj interface{}) {
+				log.Print("ClusterPodLabelConfig Delete Event")
+				// The object may have been deleted without the finalizer being run. Clean up anything left behind.
+				c, ok := obj.(*v1alpha1.ClusterPodLabelConfig)
+				if !ok {
+					tombstone, ok := obj.(cache.DeletedFinalStateUnknown)
+					if !ok {
+						return
+					}
+					if c, ok = tombstone.Obj.(*v1alpha1.ClusterPodLabelConfig); !ok {
+						return
+					}
+				}
+				ctrl.ReconcileAllPods(c)
+			},
+		},
+	)
+}
+
+// Sync adds the finalizer to a ClusterPodLabelConfig, or finalizes it once it is deleted
+func (ctrl *Controller) Sync(key interface{}) error {
+	c, err := ctrl.Lister.Get(key.(string))
+	if errors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if c.GetDeletionTimestamp() != nil {
+		return ctrl.finalize(c)
+	}
+	return ctrl.Configs.Add(c)
+}
+
+// ReconcileAllPods queues the pods targeted by a ClusterPodLabelConfig in every namespace it selects
+func (ctrl *Controller) ReconcileAllPods(c *v1alpha1.ClusterPodLabelConfig) {
+	// Only reconcile after initial sync
+	if !ctrl.HasSynced() {
+		return
+	}
+
+	selector, err := selectors.Pods(&c.Spec.PodLabelConfigSpec)
+	if err != nil {
+		log.Printf("Invalid podSelector in ClusterPodLabelConfig %s: %s", c.GetName(), err)
+		// An invalid selector never matched any pods
+		selector = labels.Nothing()
+	}
+
+	namespaces, err := ctrl.NamespaceLister.List(labels.Everything())
+	if err != nil {
+		log.Printf("Error listing namespaces: %s", err)
+		return
+	}
+	for _, ns := range namespaces {
+		namespace := ns.GetName()
+		if !selectors.ClusterConfigMatches(ctrl.NamespaceLister, c, namespace) {
+			continue
+		}
+
+		log.Printf("Queueing all pods for cplc: %s namespace: %s selector: %q\n", c.GetName(), namespace, selector.String())
+		ctrl.EnqueueNamespace(namespace, selector)
+	}
+}
+
+// finalize removes the labels and annotations set by a deleted ClusterPodLabelConfig from its pods.
+// The pods are cleaned up by the queue workers. The finalizer is only removed once no cached pod has keys
+// from the config left, until then this is checked again on every resync.
+func (ctrl *Controller) finalize(c *v1alpha1.ClusterPodLabelConfig) error {
+	// Only finalize after initial sync, otherwise labels from configs not yet in the store would be removed
+	if !ctrl.HasSynced() || !finalizers.Has(c, ctrl.Configs.Finalizer) {
+		return nil
+	}
+
+	log.Printf("Finalizing ClusterPodLabelConfig %s", c.GetName())
+
+	// Conflicts with the config are resolved in the namespaces it selected
+	ctrl.ReconcileAllPods(c)
+
+	remaining := ctrl.EnqueueOwned(corev1.NamespaceAll, labeling.ClusterOwnerPrefix+c.GetName())
+	if remaining > 0 {
+		log.Printf("Waiting for %d pods and workloads to be cleaned up before finalizing ClusterPodLabelConfig %s", remaining, c.GetName())
+		return nil
+	}
+
+	return ctrl.Configs.Remove(c)
+}
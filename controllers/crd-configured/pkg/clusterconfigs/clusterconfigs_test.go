@@ -0,0 +1,138 @@
+package clusterconfigs
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/carsonoid/kube-crds-and-controllers/controllers/crd-configured/pkg/apis/podlabeler/v1alpha1"
+	pllisters "github.com/carsonoid/kube-crds-and-controllers/controllers/crd-configured/pkg/client/listers/podlabeler/v1alpha1"
+	"github.com/carsonoid/kube-crds-and-controllers/controllers/crd-configured/pkg/finalizers"
+)
+
+const testFinalizer = "clusterpodlabelconfig.finalizers.k8s.carsonoid.net"
+
+// testController returns a controller for a single config. The namespaces queued by it are recorded in queued,
+// the finalizers written in written.
+func testController(c *v1alpha1.ClusterPodLabelConfig, owned int, queued *[]string, written *[]string) *Controller {
+	configs := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	configs.Add(c)
+
+	namespaces := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	namespaces.Add(&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "web", Labels: map[string]string{"team": "web"}}})
+	namespaces.Add(&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "db", Labels: map[string]string{"team": "db"}}})
+
+	return &Controller{
+		Lister:          pllisters.NewClusterPodLabelConfigLister(configs),
+		NamespaceLister: corelisters.NewNamespaceLister(namespaces),
+		Configs: finalizers.Kind{
+			Resource:  v1alpha1.Resource("clusterpodlabelconfigs"),
+			Finalizer: testFinalizer,
+			Indexer:   configs,
+			Update: func(obj metav1.Object) error {
+				*written = obj.GetFinalizers()
+				return nil
+			},
+		},
+		HasSynced: func() bool { return true },
+		EnqueueNamespace: func(namespace string, selector labels.Selector) {
+			*queued = append(*queued, namespace)
+		},
+		EnqueueOwned: func(namespace, owner string) int {
+			if namespace != corev1.NamespaceAll || owner != "ClusterPodLabelConfig/contacts" {
+				return 0
+			}
+			return owned
+		},
+	}
+}
+
+func TestReconcileAllPods(t *testing.T) {
+	tests := []struct {
+		name     string
+		selector *metav1.LabelSelector
+		want     []string
+	}{
+		{
+			name: "every namespace",
+			want: []string{"db", "web"},
+		},
+		{
+			name:     "selected namespace",
+			selector: &metav1.LabelSelector{MatchLabels: map[string]string{"team": "web"}},
+			want:     []string{"web"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &v1alpha1.ClusterPodLabelConfig{ObjectMeta: metav1.ObjectMeta{Name: "contacts"}}
+			c.Spec.NamespaceSelector = tt.selector
+
+			var queued, written []string
+			testController(c, 0, &queued, &written).ReconcileAllPods(c)
+
+			sort.Strings(queued)
+			if !reflect.DeepEqual(queued, tt.want) {
+				t.Errorf("queued %v, want %v", queued, tt.want)
+			}
+		})
+	}
+}
+
+func TestSync(t *testing.T) {
+	now := metav1.Now()
+
+	tests := []struct {
+		name       string
+		deleted    bool
+		finalizers []string
+		owned      int
+		// want are the finalizers written, nil expects no write
+		want []string
+	}{
+		{
+			name: "new config",
+			want: []string{testFinalizer},
+		},
+		{
+			name:       "finalizer present",
+			finalizers: []string{testFinalizer},
+		},
+		{
+			name:       "deleted config with keys left",
+			deleted:    true,
+			finalizers: []string{testFinalizer, "other"},
+			owned:      2,
+		},
+		{
+			name:       "deleted config without keys left",
+			deleted:    true,
+			finalizers: []string{testFinalizer, "other"},
+			want:       []string{"other"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &v1alpha1.ClusterPodLabelConfig{ObjectMeta: metav1.ObjectMeta{Name: "contacts", Finalizers: tt.finalizers}}
+			if tt.deleted {
+				c.SetDeletionTimestamp(&now)
+			}
+
+			var queued, written []string
+			if err := testController(c, tt.owned, &queued, &written).Sync("contacts"); err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if !reflect.DeepEqual(written, tt.want) {
+				t.Errorf("wrote finalizers %v, want %v", written, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,92 @@
+// Package exclusions decides which pods the pod labeler leaves alone.
+//
+// Pods can opt out themselves or through their namespace, and the controller excludes namespaces,
+// owner kinds and pod phases for every config.
+package exclusions
+
+import (
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Pods, namespaces and other objects with this annotation set to "true" are never labelled
+const OptOutAnnotation string = "podlabeler.k8s.carsonoid.net/ignore"
+
+// The kubelet creates mirror pods for static pods with this annotation. They are never labelled
+const MirrorPodAnnotation string = "kubernetes.io/config.mirror"
+
+// NamespaceFunc returns the namespace with the given name
+type NamespaceFunc func(name string) (*corev1.Namespace, error)
+
+// OwnerFunc returns the kind and name of the object at the top of the controller references of a pod
+type OwnerFunc func(pod *corev1.Pod) (string, string, error)
+
+// Rules are the controller wide rules for pods that are never labelled
+type Rules struct {
+	Namespaces map[string]bool
+	OwnerKinds map[string]bool
+	Phases     map[corev1.PodPhase]bool
+}
+
+// New parses the comma separated lists of the exclusion flags
+func New(namespaces, ownerKinds, phases string) Rules {
+	r := Rules{
+		Namespaces: make(map[string]bool),
+		OwnerKinds: make(map[string]bool),
+		Phases:     make(map[corev1.PodPhase]bool),
+	}
+	for _, v := range splitList(namespaces) {
+		r.Namespaces[v] = true
+	}
+	for _, v := range splitList(ownerKinds) {
+		r.OwnerKinds[v] = true
+	}
+	for _, v := range splitList(phases) {
+		r.Phases[corev1.PodPhase(v)] = true
+	}
+	return r
+}
+
+// splitList returns the non-empty values of a comma separated list
+func splitList(list string) []string {
+	values := []string{}
+	for _, v := range strings.Split(list, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+// Excluded reports if a pod is never labelled. Pods are excluded when they or their namespace opted out, when
+// they are mirror pods, or when their namespace, phase, controller kind or top-level owner kind is excluded.
+// The owner and the namespace are only looked up when the cheaper checks did not decide already.
+func (r Rules) Excluded(pod *corev1.Pod, namespace NamespaceFunc, owner OwnerFunc) bool {
+	if pod.GetAnnotations()[OptOutAnnotation] == "true" {
+		return true
+	}
+	if _, ok := pod.GetAnnotations()[MirrorPodAnnotation]; ok {
+		return true
+	}
+	if r.Namespaces[pod.GetNamespace()] || r.Phases[pod.Status.Phase] {
+		return true
+	}
+
+	if ref := metav1.GetControllerOf(pod); ref != nil && len(r.OwnerKinds) > 0 {
+		if r.OwnerKinds[ref.Kind] {
+			return true
+		}
+		if kind, _, err := owner(pod); err == nil && r.OwnerKinds[kind] {
+			return true
+		}
+	}
+
+	if pod.GetNamespace() != "" {
+		if ns, err := namespace(pod.GetNamespace()); err == nil && ns.GetAnnotations()[OptOutAnnotation] == "true" {
+			return true
+		}
+	}
+	return false
+}
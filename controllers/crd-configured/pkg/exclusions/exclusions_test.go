@@ -0,0 +1,169 @@
+package exclusions
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestNew(t *testing.T) {
+	r := New(" kube-system, monitoring ,", "DaemonSet", "")
+
+	want := Rules{
+		Namespaces: map[string]bool{"kube-system": true, "monitoring": true},
+		OwnerKinds: map[string]bool{"DaemonSet": true},
+		Phases:     map[corev1.PodPhase]bool{},
+	}
+	if !reflect.DeepEqual(r, want) {
+		t.Errorf("New() = %#v, want %#v", r, want)
+	}
+}
+
+func controlledPod(namespace, kind string) *corev1.Pod {
+	isController := true
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "web-1", Namespace: namespace}}
+	if kind != "" {
+		pod.OwnerReferences = []metav1.OwnerReference{{Kind: kind, Name: "web", Controller: &isController}}
+	}
+	return pod
+}
+
+func TestExcluded(t *testing.T) {
+	rules := New("kube-system", "DaemonSet,CronJob", "Succeeded,Failed")
+
+	namespaces := map[string]*corev1.Namespace{
+		"default": {ObjectMeta: metav1.ObjectMeta{Name: "default"}},
+		"opted-out": {ObjectMeta: metav1.ObjectMeta{
+			Name:        "opted-out",
+			Annotations: map[string]string{OptOutAnnotation: "true"},
+		}},
+	}
+	namespace := func(name string) (*corev1.Namespace, error) {
+		if ns, ok := namespaces[name]; ok {
+			return ns, nil
+		}
+		return nil, fmt.Errorf("namespace %s not found", name)
+	}
+
+	// Jobs created by CronJobs are the only owners that are followed further up
+	owner := func(pod *corev1.Pod) (string, string, error) {
+		ref := metav1.GetControllerOf(pod)
+		if ref.Kind == "Job" {
+			return "CronJob", "nightly", nil
+		}
+		return ref.Kind, ref.Name, nil
+	}
+
+	tests := []struct {
+		name string
+		pod  func() *corev1.Pod
+		want bool
+	}{
+		{
+			name: "plain pod",
+			pod:  func() *corev1.Pod { return controlledPod("default", "") },
+			want: false,
+		},
+		{
+			name: "pod opted out",
+			pod: func() *corev1.Pod {
+				pod := controlledPod("default", "")
+				pod.Annotations = map[string]string{OptOutAnnotation: "true"}
+				return pod
+			},
+			want: true,
+		},
+		{
+			name: "opt-out annotation not true",
+			pod: func() *corev1.Pod {
+				pod := controlledPod("default", "")
+				pod.Annotations = map[string]string{OptOutAnnotation: "false"}
+				return pod
+			},
+			want: false,
+		},
+		{
+			name: "mirror pod",
+			pod: func() *corev1.Pod {
+				pod := controlledPod("default", "")
+				pod.Annotations = map[string]string{MirrorPodAnnotation: "0f1c3b2a"}
+				return pod
+			},
+			want: true,
+		},
+		{
+			name: "excluded namespace",
+			pod:  func() *corev1.Pod { return controlledPod("kube-system", "") },
+			want: true,
+		},
+		{
+			name: "namespace opted out",
+			pod:  func() *corev1.Pod { return controlledPod("opted-out", "") },
+			want: true,
+		},
+		{
+			name: "unknown namespace",
+			pod:  func() *corev1.Pod { return controlledPod("missing", "") },
+			want: false,
+		},
+		{
+			name: "excluded phase",
+			pod: func() *corev1.Pod {
+				pod := controlledPod("default", "")
+				pod.Status.Phase = corev1.PodSucceeded
+				return pod
+			},
+			want: true,
+		},
+		{
+			name: "running pod",
+			pod: func() *corev1.Pod {
+				pod := controlledPod("default", "")
+				pod.Status.Phase = corev1.PodRunning
+				return pod
+			},
+			want: false,
+		},
+		{
+			name: "excluded controller kind",
+			pod:  func() *corev1.Pod { return controlledPod("default", "DaemonSet") },
+			want: true,
+		},
+		{
+			name: "excluded top-level owner kind",
+			pod:  func() *corev1.Pod { return controlledPod("default", "Job") },
+			want: true,
+		},
+		{
+			name: "other owner kind",
+			pod:  func() *corev1.Pod { return controlledPod("default", "ReplicaSet") },
+			want: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := rules.Excluded(tt.pod(), namespace, owner); got != tt.want {
+				t.Errorf("Excluded() = %t, want %t", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExcludedWithoutOwnerKinds(t *testing.T) {
+	// Without excluded owner kinds the owner is never looked up
+	owner := func(pod *corev1.Pod) (string, string, error) {
+		t.Fatalf("owner of %s looked up", pod.GetName())
+		return "", "", nil
+	}
+	namespace := func(name string) (*corev1.Namespace, error) {
+		return &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name}}, nil
+	}
+
+	if New("", "", "").Excluded(controlledPod("default", "Job"), namespace, owner) {
+		t.Errorf("pod excluded without any rules")
+	}
+}
@@ -0,0 +1,132 @@
+// Package finalizers adds and removes the finalizers of the config kinds.
+//
+// Every kind is read and written the same way: the first attempt updates the cached copy and retries after a
+// conflict read the latest version from the apiserver, because the cache may not have seen it yet.
+package finalizers
+
+import (
+	"fmt"
+	"log"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/retry"
+)
+
+// Kind reads and writes the objects of one config kind
+type Kind struct {
+	// Resource is reported in the NotFound error for objects missing from the cache
+	Resource schema.GroupResource
+	// Finalizer is added to every object of the kind
+	Finalizer string
+	// Indexer is the informer cache holding the objects
+	Indexer cache.Indexer
+	// Get reads the latest version of an object from the apiserver
+	Get func(namespace, name string) (metav1.Object, error)
+	// Update writes an object
+	Update func(obj metav1.Object) error
+}
+
+// Modify applies change to a copy of the latest version of obj and writes it with write, retrying on conflicts.
+// write is Kind.Update unless a subresource is written.
+func (k Kind) Modify(obj metav1.Object, change func(metav1.Object), write func(metav1.Object) error) error {
+	live := false
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		// Retrieve the latest version before attempting update
+		// RetryOnConflict uses exponential backoff to avoid exhausting the apiserver
+		result, getErr := k.latest(obj, live)
+		live = true
+		if getErr != nil {
+			return getErr
+		}
+
+		change(result)
+		return write(result)
+	})
+}
+
+// latest returns a copy of obj to update. The cache is read unless live is set
+func (k Kind) latest(obj metav1.Object, live bool) (metav1.Object, error) {
+	if live {
+		return k.Get(obj.GetNamespace(), obj.GetName())
+	}
+
+	cached, exists, err := k.Indexer.GetByKey(key(obj))
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, errors.NewNotFound(k.Resource, obj.GetName())
+	}
+	copied, ok := cached.(runtime.Object).DeepCopyObject().(metav1.Object)
+	if !ok {
+		return nil, fmt.Errorf("cached %s %s has no metadata", k.Resource.String(), key(obj))
+	}
+	return copied, nil
+}
+
+// Add adds the finalizer of the kind to obj unless it is already present
+func (k Kind) Add(obj metav1.Object) error {
+	if Has(obj, k.Finalizer) {
+		return nil
+	}
+
+	err := k.Modify(obj, func(result metav1.Object) {
+		if !Has(result, k.Finalizer) {
+			result.SetFinalizers(append(result.GetFinalizers(), k.Finalizer))
+		}
+	}, k.Update)
+	if err != nil {
+		log.Printf("Update failed: %+v", err)
+		return err
+	}
+
+	log.Printf("Added finalizer for: %s", key(obj))
+	return nil
+}
+
+// Remove removes the finalizer of the kind from obj
+func (k Kind) Remove(obj metav1.Object) error {
+	err := k.Modify(obj, func(result metav1.Object) {
+		result.SetFinalizers(without(result.GetFinalizers(), k.Finalizer))
+	}, k.Update)
+	if err != nil {
+		log.Printf("Update failed: %+v", err)
+		return err
+	}
+
+	log.Printf("Removed finalizer from: %s", key(obj))
+	return nil
+}
+
+// Has returns true if obj has the finalizer
+func Has(obj metav1.Object, finalizer string) bool {
+	for _, f := range obj.GetFinalizers() {
+		if f == finalizer {
+			return true
+		}
+	}
+	return false
+}
+
+// without returns the finalizers other than finalizer
+func without(finalizers []string, finalizer string) []string {
+	var kept []string
+	for _, f := range finalizers {
+		if f != finalizer {
+			kept = append(kept, f)
+		}
+	}
+	return kept
+}
+
+// key is the cache key of obj, the name of cluster-scoped objects and namespace/name otherwise
+func key(obj metav1.Object) string {
+	if obj.GetNamespace() == "" {
+		return obj.GetName()
+	}
+	return obj.GetNamespace() + "/" + obj.GetName()
+}
@@ -0,0 +1,141 @@
+package finalizers
+
+import (
+	"reflect"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/carsonoid/kube-crds-and-controllers/controllers/crd-configured/pkg/apis/podlabeler/v1alpha1"
+)
+
+const testFinalizer = "test.finalizers.k8s.carsonoid.net"
+
+func config(resourceVersion string, finalizers ...string) *v1alpha1.PodLabelConfig {
+	return &v1alpha1.PodLabelConfig{ObjectMeta: metav1.ObjectMeta{
+		Name:            "test1",
+		Namespace:       "default",
+		ResourceVersion: resourceVersion,
+		Finalizers:      finalizers,
+	}}
+}
+
+func TestKind(t *testing.T) {
+	tests := []struct {
+		name   string
+		cached *v1alpha1.PodLabelConfig
+		// live is the version on the apiserver, writes of any other version conflict
+		live   *v1alpha1.PodLabelConfig
+		remove bool
+		// want is the written config, nil expects no write
+		want    *v1alpha1.PodLabelConfig
+		wantErr bool
+	}{
+		{
+			name:   "add",
+			cached: config("1", "other"),
+			live:   config("1", "other"),
+			want:   config("1", "other", testFinalizer),
+		},
+		{
+			name:   "add present",
+			cached: config("1", testFinalizer),
+			live:   config("1", testFinalizer),
+		},
+		{
+			name:   "add to a stale cache",
+			cached: config("1"),
+			live:   config("2", "other"),
+			want:   config("2", "other", testFinalizer),
+		},
+		{
+			name:   "remove",
+			cached: config("1", "other", testFinalizer),
+			live:   config("1", "other", testFinalizer),
+			remove: true,
+			want:   config("1", "other"),
+		},
+		{
+			name:   "remove the last finalizer",
+			cached: config("1", testFinalizer),
+			live:   config("1", testFinalizer),
+			remove: true,
+			want:   config("1"),
+		},
+		{
+			name:   "remove from a stale cache",
+			cached: config("1", testFinalizer),
+			live:   config("2", testFinalizer, "other"),
+			remove: true,
+			want:   config("2", "other"),
+		},
+		{
+			name:    "remove from a deleted config",
+			cached:  config("1", testFinalizer),
+			remove:  true,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+			indexer.Add(tt.cached)
+
+			var written *v1alpha1.PodLabelConfig
+			k := Kind{
+				Resource:  v1alpha1.Resource("podlabelconfigs"),
+				Finalizer: testFinalizer,
+				Indexer:   indexer,
+				Get: func(namespace, name string) (metav1.Object, error) {
+					if tt.live == nil {
+						return nil, errors.NewNotFound(v1alpha1.Resource("podlabelconfigs"), name)
+					}
+					return tt.live.DeepCopy(), nil
+				},
+				Update: func(obj metav1.Object) error {
+					c := obj.(*v1alpha1.PodLabelConfig)
+					if tt.live == nil {
+						return errors.NewNotFound(v1alpha1.Resource("podlabelconfigs"), c.GetName())
+					}
+					if c.GetResourceVersion() != tt.live.GetResourceVersion() {
+						return errors.NewConflict(v1alpha1.Resource("podlabelconfigs"), c.GetName(), nil)
+					}
+					written = c
+					return nil
+				},
+			}
+
+			var err error
+			if tt.remove {
+				err = k.Remove(tt.cached)
+			} else {
+				err = k.Add(tt.cached)
+			}
+
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if !reflect.DeepEqual(written, tt.want) {
+				t.Errorf("wrote %v, want %v", written, tt.want)
+			}
+		})
+	}
+}
+
+func TestHas(t *testing.T) {
+	if !Has(config("1", "other", testFinalizer), testFinalizer) {
+		t.Errorf("finalizer not found")
+	}
+	if Has(config("1", "other"), testFinalizer) {
+		t.Errorf("missing finalizer found")
+	}
+}
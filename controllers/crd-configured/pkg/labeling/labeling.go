@@ -0,0 +1,341 @@
+// Package labeling decides which labels and annotations the configs set on a pod.
+//
+// Configs are ordered by precedence and the first config to set a key wins it. The keys each config set are
+// recorded in a tracking annotation on the pod, so keys no config wants anymore can be removed again without
+// touching anything set by someone else. Pod templates are labelled by wrapping them in a pod.
+package labeling
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/carsonoid/kube-crds-and-controllers/controllers/crd-configured/pkg/apis/podlabeler/v1alpha1"
+	"github.com/carsonoid/kube-crds-and-controllers/controllers/crd-configured/pkg/templates"
+)
+
+// The tracking annotations hold a json map of config name to the label or annotation keys it set
+const ManagedLabelsAnnotation string = "podlabeler.k8s.carsonoid.net/managed-labels"
+const ManagedAnnotationsAnnotation string = "podlabeler.k8s.carsonoid.net/managed-annotations"
+
+// The keys set by cluster-scoped configs are recorded under the name of the config with its kind as a prefix,
+// so they never collide with the PodLabelConfigs of a namespace
+const ClusterOwnerPrefix string = "ClusterPodLabelConfig/"
+const ResourceOwnerPrefix string = "ResourceLabelConfig/"
+
+// Source is a PodLabelConfig, ClusterPodLabelConfig or ResourceLabelConfig which applies to a pod
+type Source struct {
+	// Owner identifies the config in logs and in the managed keys annotations
+	Owner string
+	// ClusterScoped sources lose ties against PodLabelConfigs from the pod's namespace
+	ClusterScoped bool
+	// Audit sources win keys by priority like every other source, but the keys they win are neither set nor removed
+	Audit bool
+	// PodOnly sources win keys by priority, but the keys they win are not set. Used for configs
+	// without podTemplates when labelling pod templates, and for templated labels when admitting pods
+	PodOnly bool
+	Spec    *v1alpha1.PodLabelConfigSpec
+}
+
+// NamespacedSource turns a PodLabelConfig into a Source
+func NamespacedSource(c *v1alpha1.PodLabelConfig) Source {
+	return Source{Owner: c.GetName(), Audit: c.Spec.Mode == v1alpha1.PodLabelConfigModeAudit, Spec: &c.Spec}
+}
+
+// ClusterSource turns a ClusterPodLabelConfig into a Source
+func ClusterSource(c *v1alpha1.ClusterPodLabelConfig) Source {
+	return Source{Owner: ClusterOwnerPrefix + c.GetName(), ClusterScoped: true, Audit: c.Spec.Mode == v1alpha1.PodLabelConfigModeAudit, Spec: &c.Spec.PodLabelConfigSpec}
+}
+
+// ResourceSource turns a ResourceLabelConfig into a Source, so objects are labelled by the same merge logic
+// as pods. Templated label values are rendered against pods and are skipped.
+func ResourceSource(c *v1alpha1.ResourceLabelConfig) Source {
+	spec := &v1alpha1.PodLabelConfigSpec{
+		Labels:      make(map[string]string),
+		Annotations: c.Spec.Annotations,
+		Priority:    c.Spec.Priority,
+		Mode:        c.Spec.Mode,
+	}
+	for k, v := range c.Spec.Labels {
+		if !templates.IsTemplate(v) {
+			spec.Labels[k] = v
+		}
+	}
+	return Source{Owner: ResourceOwnerPrefix + c.GetName(), ClusterScoped: true, Audit: c.Spec.Mode == v1alpha1.PodLabelConfigModeAudit, Spec: spec}
+}
+
+// HasPrecedence returns true if config a wins over config b when both set the same key.
+// The higher priority wins. With equal priority PodLabelConfigs win over ClusterPodLabelConfigs
+// and are then ordered by name.
+func HasPrecedence(a, b Source) bool {
+	if a.Spec.Priority != b.Spec.Priority {
+		return a.Spec.Priority > b.Spec.Priority
+	}
+	if a.ClusterScoped != b.ClusterScoped {
+		return !a.ClusterScoped
+	}
+	return a.Owner < b.Owner
+}
+
+// ResolveKeys decides which config owns each key. configs must be sorted by precedence, the first
+// config to set a key wins. It returns the winning value of each key and the keys owned by each config.
+// Configs in audit mode and podOnly configs win keys like any other config, but their keys are left out of
+// the values and owners so they are never written. The keys won by configs in audit mode are returned too.
+func ResolveKeys(configs []Source, keysFunc func(*v1alpha1.PodLabelConfigSpec) map[string]string) (map[string]string, map[string][]string, map[string]bool) {
+	values := make(map[string]string)
+	owners := make(map[string][]string)
+	audited := make(map[string]bool)
+	claimed := make(map[string]bool)
+	for _, c := range configs {
+		m := keysFunc(c.Spec)
+		for _, k := range SortedKeys(m) {
+			if claimed[k] {
+				// owned by a config with precedence
+				continue
+			}
+			claimed[k] = true
+
+			if c.Audit {
+				audited[k] = true
+				continue
+			}
+			if c.PodOnly {
+				continue
+			}
+			values[k] = m[k]
+			owners[c.Owner] = append(owners[c.Owner], k)
+		}
+	}
+	return values, owners, audited
+}
+
+// KeepAuditedKeys keeps the keys already recorded for configs in audit mode, so switching a config
+// to audit mode does not remove the keys it set before. Keys won by a config in audit mode also stay
+// recorded for the config that set them, so an audited config never removes keys of other configs.
+func KeepAuditedKeys(pod *corev1.Pod, annotation string, configs []Source, audited map[string]bool, owners map[string][]string) {
+	recorded := ManagedKeys(pod, annotation)
+	for _, c := range configs {
+		if c.Audit && len(recorded[c.Owner]) > 0 {
+			owners[c.Owner] = recorded[c.Owner]
+		}
+	}
+
+	for owner, keys := range recorded {
+		kept := make(map[string]bool)
+		for _, k := range owners[owner] {
+			kept[k] = true
+		}
+		changed := false
+		for _, k := range keys {
+			if audited[k] && !kept[k] {
+				owners[owner] = append(owners[owner], k)
+				changed = true
+			}
+		}
+		if changed {
+			sort.Strings(owners[owner])
+		}
+	}
+}
+
+// PresentKeys removes the keys from owners that configs in IfAbsent mode would set, but that the pod already has
+// from someone else. Keys the config set itself are kept so changes to the config still apply. It returns the
+// removed keys with the config that wanted them.
+func PresentKeys(pod *corev1.Pod, m map[string]string, annotation string, configs []Source, owners map[string][]string) map[string]string {
+	present := make(map[string]string)
+	recorded := ManagedKeys(pod, annotation)
+	for _, c := range configs {
+		if c.Spec.Mode != v1alpha1.PodLabelConfigModeIfAbsent || len(owners[c.Owner]) == 0 {
+			continue
+		}
+
+		own := make(map[string]bool)
+		for _, k := range recorded[c.Owner] {
+			own[k] = true
+		}
+
+		kept := []string{}
+		for _, k := range owners[c.Owner] {
+			if _, ok := m[k]; ok && !own[k] {
+				present[k] = c.Owner
+				continue
+			}
+			kept = append(kept, k)
+		}
+		if len(kept) == 0 {
+			delete(owners, c.Owner)
+		} else {
+			owners[c.Owner] = kept
+		}
+	}
+	return present
+}
+
+// FindConflicts returns a description of every key of c that is set to a different value by a
+// config with precedence over it.
+func FindConflicts(c Source, configs []Source) []string {
+	conflicts := []string{}
+	for _, other := range configs {
+		if other.Owner == c.Owner || !HasPrecedence(other, c) {
+			continue
+		}
+		for _, k := range SortedKeys(c.Spec.Labels) {
+			if v, ok := other.Spec.Labels[k]; ok && v != c.Spec.Labels[k] {
+				conflicts = append(conflicts, fmt.Sprintf("label %s is set to %q by %s", k, v, other.Owner))
+			}
+		}
+		for _, k := range SortedKeys(c.Spec.Annotations) {
+			if v, ok := other.Spec.Annotations[k]; ok && v != c.Spec.Annotations[k] {
+				conflicts = append(conflicts, fmt.Sprintf("annotation %s is set to %q by %s", k, v, other.Owner))
+			}
+		}
+	}
+	return conflicts
+}
+
+// SortedKeys returns the keys of m in order, so keys are always resolved and reported the same way
+func SortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// ManagedKeys reads the keys each PodLabelConfig has set on a pod from the given tracking annotation.
+// The annotation holds a json map of config name to keys
+func ManagedKeys(pod *corev1.Pod, annotation string) map[string][]string {
+	managed := make(map[string][]string)
+	if v, ok := pod.GetAnnotations()[annotation]; ok {
+		if err := json.Unmarshal([]byte(v), &managed); err != nil {
+			log.Printf("Ignoring invalid %s annotation on pod %s: %s", annotation, pod.GetName(), err)
+		}
+	}
+	return managed
+}
+
+// ReconcileManagedKeys removes the keys from m which a config previously set but no config wants anymore.
+// Keys which were never recorded in the tracking annotation are never touched, so anything set by
+// a human is left alone. The tracking annotation is then updated to the desired set of keys.
+func ReconcileManagedKeys(pod *corev1.Pod, kind string, m map[string]string, annotation string, desired map[string][]string) bool {
+	changed := false
+
+	wanted := make(map[string]bool)
+	for _, keys := range desired {
+		for _, k := range keys {
+			wanted[k] = true
+		}
+	}
+
+	for name, keys := range ManagedKeys(pod, annotation) {
+		for _, k := range keys {
+			if _, ok := m[k]; ok && !wanted[k] {
+				log.Printf("Pod %s no longer needs %s from %s: %s", pod.GetName(), kind, name, k)
+				delete(m, k)
+				changed = true
+			}
+		}
+	}
+
+	// Record the new set of managed keys
+	if len(desired) == 0 {
+		if _, ok := pod.Annotations[annotation]; ok {
+			delete(pod.Annotations, annotation)
+			changed = true
+		}
+		return changed
+	}
+
+	data, err := json.Marshal(desired)
+	if err != nil {
+		log.Printf("Error encoding %s annotation for pod %s: %s", annotation, pod.GetName(), err)
+		return changed
+	}
+	if pod.Annotations[annotation] != string(data) {
+		pod.Annotations[annotation] = string(data)
+		changed = true
+	}
+
+	return changed
+}
+
+// HasKeysFrom reports if the tracking annotations of a pod record any label or annotation set by owner
+func HasKeysFrom(pod *corev1.Pod, owner string) bool {
+	return len(ManagedKeys(pod, ManagedLabelsAnnotation)[owner]) > 0 ||
+		len(ManagedKeys(pod, ManagedAnnotationsAnnotation)[owner]) > 0
+}
+
+// HasManagedKeys reports if a pod has a tracking annotation, so some config set a label or annotation on it
+func HasManagedKeys(pod *corev1.Pod) bool {
+	_, labels := pod.GetAnnotations()[ManagedLabelsAnnotation]
+	_, annotations := pod.GetAnnotations()[ManagedAnnotationsAnnotation]
+	return labels || annotations
+}
+
+// TemplatePod wraps a copy of a pod template in a pod, so the pod template can be labelled like a pod
+func TemplatePod(name, namespace string, template *corev1.PodTemplateSpec) *corev1.Pod {
+	t := template.DeepCopy()
+	pod := &corev1.Pod{ObjectMeta: t.ObjectMeta, Spec: t.Spec}
+	pod.SetName(name)
+	pod.SetNamespace(namespace)
+	return pod
+}
+
+// TemplateSources prepares the configs that match a pod template. Configs without podTemplates are marked
+// PodOnly, they still win keys by priority so a template never gets a value that the pods would be patched
+// away from again, but their own values are never written to templates. Templated label values depend on
+// the pod they are rendered for and are left to the pods.
+func TemplateSources(configs []Source) []Source {
+	sources := make([]Source, 0, len(configs))
+	for _, c := range configs {
+		if !c.Spec.PodTemplates {
+			c.PodOnly = true
+		}
+
+		spec := *c.Spec
+		spec.Labels = make(map[string]string)
+		for k, v := range c.Spec.Labels {
+			if !templates.IsTemplate(v) {
+				spec.Labels[k] = v
+			}
+		}
+		c.Spec = &spec
+		sources = append(sources, c)
+	}
+	return sources
+}
+
+// AdmissionSources prepares the configs that match a pod being created. Templated label values can use fields
+// that are only set after the pod is created, like the node name, and are left to the controller. Their keys
+// are split off into a PodOnly copy of the config, so they are still won by priority and a config with less
+// precedence never sets them in the meantime.
+func AdmissionSources(configs []Source) []Source {
+	sources := make([]Source, 0, len(configs))
+	for _, c := range configs {
+		static, templated := *c.Spec, *c.Spec
+		static.Labels = make(map[string]string)
+		templated.Labels = make(map[string]string)
+		templated.Annotations = nil
+		for k, v := range c.Spec.Labels {
+			if templates.IsTemplate(v) {
+				templated.Labels[k] = v
+			} else {
+				static.Labels[k] = v
+			}
+		}
+
+		if len(templated.Labels) > 0 {
+			t := c
+			t.PodOnly = true
+			t.Spec = &templated
+			sources = append(sources, t)
+		}
+		c.Spec = &static
+		sources = append(sources, c)
+	}
+	return sources
+}
@@ -0,0 +1,491 @@
+package labeling
+
+import (
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/carsonoid/kube-crds-and-controllers/controllers/crd-configured/pkg/apis/podlabeler/v1alpha1"
+)
+
+func source(owner string, priority int32, labels map[string]string) Source {
+	return Source{Owner: owner, Spec: &v1alpha1.PodLabelConfigSpec{Priority: priority, Labels: labels}}
+}
+
+func specLabels(spec *v1alpha1.PodLabelConfigSpec) map[string]string {
+	return spec.Labels
+}
+
+// podWithManaged returns a pod with labels which records the given managed labels
+func podWithManaged(labels map[string]string, managed string) *corev1.Pod {
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "web-1", Labels: labels, Annotations: map[string]string{}}}
+	if managed != "" {
+		pod.Annotations[ManagedLabelsAnnotation] = managed
+	}
+	return pod
+}
+
+func TestResourceSource(t *testing.T) {
+	c := &v1alpha1.ResourceLabelConfig{ObjectMeta: metav1.ObjectMeta{Name: "services"}}
+	c.Spec.Labels = map[string]string{"team": "web", "node": "{{ .Spec.NodeName }}"}
+	c.Spec.Mode = v1alpha1.PodLabelConfigModeAudit
+
+	source := ResourceSource(c)
+	if source.Owner != "ResourceLabelConfig/services" || !source.ClusterScoped || !source.Audit {
+		t.Errorf("source = %#v, want a cluster-scoped audit source owned by ResourceLabelConfig/services", source)
+	}
+	if want := map[string]string{"team": "web"}; !reflect.DeepEqual(source.Spec.Labels, want) {
+		t.Errorf("labels = %v, want %v without the templated value", source.Spec.Labels, want)
+	}
+}
+
+func TestHasPrecedence(t *testing.T) {
+	cluster := func(s Source) Source {
+		s.ClusterScoped = true
+		return s
+	}
+
+	tests := []struct {
+		name string
+		a, b Source
+		want bool
+	}{
+		{
+			name: "higher priority",
+			a:    source("b", 10, nil),
+			b:    source("a", 1, nil),
+			want: true,
+		},
+		{
+			name: "lower priority",
+			a:    source("a", 1, nil),
+			b:    source("b", 10, nil),
+			want: false,
+		},
+		{
+			name: "higher priority cluster config",
+			a:    cluster(source("a", 10, nil)),
+			b:    source("b", 1, nil),
+			want: true,
+		},
+		{
+			name: "namespaced config wins tie",
+			a:    source("b", 1, nil),
+			b:    cluster(source("a", 1, nil)),
+			want: true,
+		},
+		{
+			name: "cluster config loses tie",
+			a:    cluster(source("a", 1, nil)),
+			b:    source("b", 1, nil),
+			want: false,
+		},
+		{
+			name: "name breaks tie",
+			a:    source("a", 1, nil),
+			b:    source("b", 1, nil),
+			want: true,
+		},
+		{
+			name: "same config",
+			a:    source("a", 1, nil),
+			b:    source("a", 1, nil),
+			want: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := HasPrecedence(tt.a, tt.b); got != tt.want {
+				t.Errorf("HasPrecedence() = %t, want %t", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolveKeys(t *testing.T) {
+	audit := source("audit", 0, map[string]string{"team": "audit", "tier": "audit"})
+	audit.Audit = true
+	podOnly := source("pod-only", 0, map[string]string{"env": "pod", "team": "pod"})
+	podOnly.PodOnly = true
+
+	tests := []struct {
+		name        string
+		configs     []Source
+		wantValues  map[string]string
+		wantOwners  map[string][]string
+		wantAudited map[string]bool
+	}{
+		{
+			name:        "no configs",
+			wantValues:  map[string]string{},
+			wantOwners:  map[string][]string{},
+			wantAudited: map[string]bool{},
+		},
+		{
+			name: "first config wins",
+			configs: []Source{
+				source("first", 10, map[string]string{"team": "web", "env": "prod"}),
+				source("second", 1, map[string]string{"team": "db", "tier": "backend"}),
+			},
+			wantValues:  map[string]string{"team": "web", "env": "prod", "tier": "backend"},
+			wantOwners:  map[string][]string{"first": {"env", "team"}, "second": {"tier"}},
+			wantAudited: map[string]bool{},
+		},
+		{
+			name: "audit config claims keys without writing them",
+			configs: []Source{
+				audit,
+				source("second", 0, map[string]string{"team": "web", "env": "prod"}),
+			},
+			wantValues:  map[string]string{"env": "prod"},
+			wantOwners:  map[string][]string{"second": {"env"}},
+			wantAudited: map[string]bool{"team": true, "tier": true},
+		},
+		{
+			name: "pod only config claims keys without writing them",
+			configs: []Source{
+				podOnly,
+				source("second", 0, map[string]string{"team": "web", "tier": "frontend"}),
+			},
+			wantValues:  map[string]string{"tier": "frontend"},
+			wantOwners:  map[string][]string{"second": {"tier"}},
+			wantAudited: map[string]bool{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values, owners, audited := ResolveKeys(tt.configs, specLabels)
+			if !reflect.DeepEqual(values, tt.wantValues) {
+				t.Errorf("values = %v, want %v", values, tt.wantValues)
+			}
+			if !reflect.DeepEqual(owners, tt.wantOwners) {
+				t.Errorf("owners = %v, want %v", owners, tt.wantOwners)
+			}
+			if !reflect.DeepEqual(audited, tt.wantAudited) {
+				t.Errorf("audited = %v, want %v", audited, tt.wantAudited)
+			}
+		})
+	}
+}
+
+func TestKeepAuditedKeys(t *testing.T) {
+	audit := source("audit", 10, map[string]string{"team": "audit"})
+	audit.Audit = true
+	configs := []Source{audit, source("other", 0, map[string]string{"env": "prod"})}
+
+	// audit set team before it was switched to audit mode, other set tier which audit now wins
+	pod := podWithManaged(map[string]string{"team": "web", "tier": "frontend", "env": "prod"},
+		`{"audit":["team"],"other":["env","tier"]}`)
+	owners := map[string][]string{"other": {"env"}}
+
+	KeepAuditedKeys(pod, ManagedLabelsAnnotation, configs, map[string]bool{"team": true, "tier": true}, owners)
+
+	want := map[string][]string{"audit": {"team"}, "other": {"env", "tier"}}
+	if !reflect.DeepEqual(owners, want) {
+		t.Errorf("owners = %v, want %v", owners, want)
+	}
+}
+
+func TestPresentKeys(t *testing.T) {
+	ifAbsent := func(s Source) Source {
+		s.Spec.Mode = v1alpha1.PodLabelConfigModeIfAbsent
+		return s
+	}
+
+	tests := []struct {
+		name        string
+		labels      map[string]string
+		managed     string
+		configs     []Source
+		owners      map[string][]string
+		wantOwners  map[string][]string
+		wantPresent map[string]string
+	}{
+		{
+			name:        "always mode overwrites",
+			labels:      map[string]string{"team": "manual"},
+			configs:     []Source{source("web", 0, map[string]string{"team": "web"})},
+			owners:      map[string][]string{"web": {"team"}},
+			wantOwners:  map[string][]string{"web": {"team"}},
+			wantPresent: map[string]string{},
+		},
+		{
+			name:        "key set by someone else",
+			labels:      map[string]string{"team": "manual"},
+			configs:     []Source{ifAbsent(source("web", 0, map[string]string{"team": "web", "env": "prod"}))},
+			owners:      map[string][]string{"web": {"env", "team"}},
+			wantOwners:  map[string][]string{"web": {"env"}},
+			wantPresent: map[string]string{"team": "web"},
+		},
+		{
+			name:        "every key set by someone else",
+			labels:      map[string]string{"team": "manual"},
+			configs:     []Source{ifAbsent(source("web", 0, map[string]string{"team": "web"}))},
+			owners:      map[string][]string{"web": {"team"}},
+			wantOwners:  map[string][]string{},
+			wantPresent: map[string]string{"team": "web"},
+		},
+		{
+			name:        "key set by the config itself is kept",
+			labels:      map[string]string{"team": "old"},
+			managed:     `{"web":["team"]}`,
+			configs:     []Source{ifAbsent(source("web", 0, map[string]string{"team": "web"}))},
+			owners:      map[string][]string{"web": {"team"}},
+			wantOwners:  map[string][]string{"web": {"team"}},
+			wantPresent: map[string]string{},
+		},
+		{
+			name:        "key set by another config",
+			labels:      map[string]string{"team": "old"},
+			managed:     `{"other":["team"]}`,
+			configs:     []Source{ifAbsent(source("web", 0, map[string]string{"team": "web"}))},
+			owners:      map[string][]string{"web": {"team"}},
+			wantOwners:  map[string][]string{},
+			wantPresent: map[string]string{"team": "web"},
+		},
+		{
+			name:        "missing key",
+			labels:      map[string]string{},
+			configs:     []Source{ifAbsent(source("web", 0, map[string]string{"team": "web"}))},
+			owners:      map[string][]string{"web": {"team"}},
+			wantOwners:  map[string][]string{"web": {"team"}},
+			wantPresent: map[string]string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pod := podWithManaged(tt.labels, tt.managed)
+			present := PresentKeys(pod, pod.Labels, ManagedLabelsAnnotation, tt.configs, tt.owners)
+			if !reflect.DeepEqual(tt.owners, tt.wantOwners) {
+				t.Errorf("owners = %v, want %v", tt.owners, tt.wantOwners)
+			}
+			if !reflect.DeepEqual(present, tt.wantPresent) {
+				t.Errorf("present = %v, want %v", present, tt.wantPresent)
+			}
+		})
+	}
+}
+
+func TestManagedKeys(t *testing.T) {
+	tests := []struct {
+		name    string
+		managed string
+		want    map[string][]string
+	}{
+		{name: "no annotation", want: map[string][]string{}},
+		{name: "recorded keys", managed: `{"web":["env","team"]}`, want: map[string][]string{"web": {"env", "team"}}},
+		{name: "invalid annotation", managed: `["team"]`, want: map[string][]string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ManagedKeys(podWithManaged(nil, tt.managed), ManagedLabelsAnnotation); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ManagedKeys() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReconcileManagedKeys(t *testing.T) {
+	tests := []struct {
+		name        string
+		labels      map[string]string
+		managed     string
+		desired     map[string][]string
+		wantLabels  map[string]string
+		wantManaged string
+		wantChanged bool
+	}{
+		{
+			name:        "nothing managed",
+			labels:      map[string]string{"team": "manual"},
+			desired:     map[string][]string{},
+			wantLabels:  map[string]string{"team": "manual"},
+			wantChanged: false,
+		},
+		{
+			name:        "record new keys",
+			labels:      map[string]string{"team": "web"},
+			desired:     map[string][]string{"web": {"team"}},
+			wantLabels:  map[string]string{"team": "web"},
+			wantManaged: `{"web":["team"]}`,
+			wantChanged: true,
+		},
+		{
+			name:        "unchanged",
+			labels:      map[string]string{"team": "web"},
+			managed:     `{"web":["team"]}`,
+			desired:     map[string][]string{"web": {"team"}},
+			wantLabels:  map[string]string{"team": "web"},
+			wantManaged: `{"web":["team"]}`,
+			wantChanged: false,
+		},
+		{
+			name:        "remove keys no config wants",
+			labels:      map[string]string{"team": "web", "env": "prod", "owner": "manual"},
+			managed:     `{"web":["env","team"]}`,
+			desired:     map[string][]string{"web": {"team"}},
+			wantLabels:  map[string]string{"team": "web", "owner": "manual"},
+			wantManaged: `{"web":["team"]}`,
+			wantChanged: true,
+		},
+		{
+			name:        "key moved to another config",
+			labels:      map[string]string{"team": "web"},
+			managed:     `{"web":["team"]}`,
+			desired:     map[string][]string{"other": {"team"}},
+			wantLabels:  map[string]string{"team": "web"},
+			wantManaged: `{"other":["team"]}`,
+			wantChanged: true,
+		},
+		{
+			name:        "remove annotation with the last key",
+			labels:      map[string]string{"team": "web", "owner": "manual"},
+			managed:     `{"web":["team"]}`,
+			desired:     map[string][]string{},
+			wantLabels:  map[string]string{"owner": "manual"},
+			wantChanged: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pod := podWithManaged(tt.labels, tt.managed)
+			changed := ReconcileManagedKeys(pod, "label", pod.Labels, ManagedLabelsAnnotation, tt.desired)
+			if changed != tt.wantChanged {
+				t.Errorf("changed = %t, want %t", changed, tt.wantChanged)
+			}
+			if !reflect.DeepEqual(pod.Labels, tt.wantLabels) {
+				t.Errorf("labels = %v, want %v", pod.Labels, tt.wantLabels)
+			}
+			if got := pod.Annotations[ManagedLabelsAnnotation]; got != tt.wantManaged {
+				t.Errorf("managed = %q, want %q", got, tt.wantManaged)
+			}
+		})
+	}
+}
+
+func TestHasKeysFrom(t *testing.T) {
+	pod := podWithManaged(map[string]string{"team": "web"}, `{"web":["team"]}`)
+	pod.Annotations[ManagedAnnotationsAnnotation] = `{"ClusterPodLabelConfig/contacts":["contact"]}`
+
+	for owner, want := range map[string]bool{"web": true, "ClusterPodLabelConfig/contacts": true, "other": false} {
+		if got := HasKeysFrom(pod, owner); got != want {
+			t.Errorf("HasKeysFrom(%s) = %t, want %t", owner, got, want)
+		}
+	}
+}
+
+func TestHasManagedKeys(t *testing.T) {
+	annotated := podWithManaged(nil, "")
+	annotated.Annotations[ManagedAnnotationsAnnotation] = `{"web":["contact"]}`
+
+	tests := []struct {
+		name string
+		pod  *corev1.Pod
+		want bool
+	}{
+		{name: "no annotations", pod: &corev1.Pod{}, want: false},
+		{name: "untracked keys", pod: podWithManaged(map[string]string{"team": "manual"}, ""), want: false},
+		{name: "tracked labels", pod: podWithManaged(map[string]string{"team": "web"}, `{"web":["team"]}`), want: true},
+		{name: "tracked annotations", pod: annotated, want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := HasManagedKeys(tt.pod); got != tt.want {
+				t.Errorf("HasManagedKeys() = %t, want %t", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFindConflicts(t *testing.T) {
+	web := source("web", 1, map[string]string{"team": "web", "env": "prod"})
+	configs := []Source{
+		source("higher", 10, map[string]string{"team": "platform", "env": "prod"}),
+		web,
+		source("lower", 0, map[string]string{"env": "dev"}),
+	}
+
+	want := []string{`label team is set to "platform" by higher`}
+	if got := FindConflicts(web, configs); !reflect.DeepEqual(got, want) {
+		t.Errorf("FindConflicts() = %v, want %v", got, want)
+	}
+}
+
+func TestTemplatePod(t *testing.T) {
+	template := &corev1.PodTemplateSpec{
+		ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": "web"}},
+		Spec:       corev1.PodSpec{NodeName: "node-1"},
+	}
+
+	pod := TemplatePod("Deployment/default/web", "default", template)
+	if pod.GetName() != "Deployment/default/web" || pod.GetNamespace() != "default" {
+		t.Errorf("pod is %s/%s, want default/Deployment/default/web", pod.GetNamespace(), pod.GetName())
+	}
+	if pod.Labels["app"] != "web" || pod.Spec.NodeName != "node-1" {
+		t.Errorf("pod = %#v, want the metadata and spec of the template", pod)
+	}
+
+	// The template is copied, labelling the pod must not change it
+	pod.Labels["team"] = "web"
+	if _, ok := template.Labels["team"]; ok {
+		t.Errorf("template changed to %#v", template)
+	}
+}
+
+func TestTemplateSources(t *testing.T) {
+	templated := source("templated", 0, map[string]string{"team": "web", "node": "{{ .Spec.NodeName }}"})
+	templated.Spec.PodTemplates = true
+	podOnly := source("pod-only", 0, map[string]string{"env": "prod"})
+
+	sources := TemplateSources([]Source{templated, podOnly})
+	if len(sources) != 2 {
+		t.Fatalf("got %d sources, want 2", len(sources))
+	}
+
+	if sources[0].PodOnly {
+		t.Errorf("config with podTemplates marked pod only")
+	}
+	if want := map[string]string{"team": "web"}; !reflect.DeepEqual(sources[0].Spec.Labels, want) {
+		t.Errorf("labels = %v, want %v without the templated value", sources[0].Spec.Labels, want)
+	}
+	if !sources[1].PodOnly {
+		t.Errorf("config without podTemplates not marked pod only")
+	}
+
+	// The configs are shared with the cache and must not be changed
+	if len(templated.Spec.Labels) != 2 {
+		t.Errorf("config changed to %v", templated.Spec.Labels)
+	}
+}
+
+func TestAdmissionSources(t *testing.T) {
+	templated := source("templated", 10, map[string]string{"team": "web", "node": "{{ .Spec.NodeName }}"})
+	lower := source("lower", 0, map[string]string{"node": "unknown", "env": "prod"})
+
+	sources := AdmissionSources([]Source{templated, lower})
+	if len(sources) != 3 {
+		t.Fatalf("got %d sources, want 3", len(sources))
+	}
+
+	// The templated key is still won by the config, but nothing is set for it
+	values, owners, _ := ResolveKeys(sources, specLabels)
+	if want := map[string]string{"team": "web", "env": "prod"}; !reflect.DeepEqual(values, want) {
+		t.Errorf("values = %v, want %v", values, want)
+	}
+	if want := map[string][]string{"templated": {"team"}, "lower": {"env"}}; !reflect.DeepEqual(owners, want) {
+		t.Errorf("owners = %v, want %v", owners, want)
+	}
+
+	// The configs are shared with the cache and must not be changed
+	if len(templated.Spec.Labels) != 2 {
+		t.Errorf("config changed to %v", templated.Spec.Labels)
+	}
+}
@@ -0,0 +1,422 @@
+// Package podconfigs runs the controller for PodLabelConfigs.
+//
+// Changes to a config queue the pods and workloads it selects, which are labelled by their own controllers, and
+// the config itself. Once the pods are processed the config worker writes the finalizer, the status and the
+// Conflicting condition of the config. The finalizer of a deleted config is only removed once no pod or workload
+// has keys set by it anymore.
+package podconfigs
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+
+	"github.com/carsonoid/kube-crds-and-controllers/controllers/crd-configured/pkg/apis/podlabeler/v1alpha1"
+	plclient "github.com/carsonoid/kube-crds-and-controllers/controllers/crd-configured/pkg/client/clientset/versioned"
+	pllisters "github.com/carsonoid/kube-crds-and-controllers/controllers/crd-configured/pkg/client/listers/podlabeler/v1alpha1"
+	"github.com/carsonoid/kube-crds-and-controllers/controllers/crd-configured/pkg/finalizers"
+	"github.com/carsonoid/kube-crds-and-controllers/controllers/crd-configured/pkg/labeling"
+	"github.com/carsonoid/kube-crds-and-controllers/controllers/crd-configured/pkg/selectors"
+	"github.com/carsonoid/kube-crds-and-controllers/controllers/crd-configured/pkg/templates"
+)
+
+// Controller writes the finalizers, status and conditions of PodLabelConfigs and queues the pods they select
+type Controller struct {
+	Lister   pllisters.PodLabelConfigLister
+	Informer cache.SharedIndexInformer
+	Client   plclient.Interface
+	// ClusterPodLabelConfigs selecting the namespace of a config are taken into account for its conflicts
+	ClusterLister   pllisters.ClusterPodLabelConfigLister
+	NamespaceLister corelisters.NamespaceLister
+
+	// Configs adds and removes the finalizer of the configs
+	Configs finalizers.Kind
+
+	// HasSynced reports if the initial sync is complete. Nothing is reconciled, finalized or written before it
+	HasSynced func() bool
+	// CoalesceWindow delays queued configs like the pods queued before them, so the pods reach the pod queue first
+	CoalesceWindow time.Duration
+	// EnqueuePods queues the pods and workloads of a namespace matching selector
+	EnqueuePods func(namespace string, selector labels.Selector)
+	// EnqueueOwned queues the pods and workloads that still have keys set by owner and returns how many were found
+	EnqueueOwned func(namespace, owner string) int
+	// Pods returns the cached pods of a namespace, IsExcluded reports the ones that are never labelled
+	Pods       func(namespace string) []*corev1.Pod
+	IsExcluded func(pod *corev1.Pod) bool
+
+	// Queue holds the keys of the configs to sync. It is created by Start
+	Queue workqueue.RateLimitingInterface
+}
+
+// Start creates the config queue and registers the PodLabelConfig event handlers on the
+// shared informer. The handlers only queue pods and configs, everything written to a config is left to the
+// config worker. The informer itself is started with the other informers of its factory.
+func (ctrl *Controller) Start() {
+	log.Print("Starting PodLabelConfig Controller")
+
+	ctrl.Queue = workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "configQueue")
+
+	// The informer resyncs so that failed finalizations are retried and the pod counts refreshed
+	ctrl.Informer.AddEventHandler(
+		cache.ResourceEventHandlerFuncs{
+			AddFunc: func(obj interface{}) {
+				log.Print("PodLabelConfig Add Event")
+				c := obj.(*v1alpha1.PodLabelConfig)
+				if c.GetDeletionTimestamp() != nil {
+					ctrl.Enqueue(c)
+					return
+				}
+				ctrl.ReconcileAllPods(c)
+			},
+			UpdateFunc: func(oldobj interface{}, newobj interface{}) {
+				log.Print("PodLabelConfig Update Event")
+				oldConfig := oldobj.(*v1alpha1.PodLabelConfig)
+				newConfig := newobj.(*v1alpha1.PodLabelConfig)
+
+				// Configs set for deletion get their keys removed from all pods by the finalizer
+				if newConfig.GetDeletionTimestamp() != nil {
+					ctrl.Enqueue(newConfig)
+					return
+				}
+
+				// Make sure the spec was actually changed. Status and metadata updates do not affect pods
+				specChanged := !equality.Semantic.DeepEqual(oldConfig.Spec, newConfig.Spec)
+
+				// Pods which are no longer selected need their keys removed
+				if specChanged && !equality.Semantic.DeepEqual(oldConfig.Spec.PodSelector, newConfig.Spec.PodSelector) {
+					if err := ctrl.enqueuePods(oldConfig); err != nil {
+						log.Printf("Error reconciling pods for plc: %s", err)
+					}
+				}
+
+				// Configs that were never reconciled at this generation are reconciled now. Configs that failed to
+				// reconcile are only retried on resync, their own status updates would trigger a retry loop otherwise.
+				// Anything else only has its status checked, which writes nothing unless the pod counts changed
+				resync := oldConfig.GetResourceVersion() == newConfig.GetResourceVersion()
+				if specChanged || newConfig.Status.ObservedGeneration != newConfig.GetGeneration() || (resync && isErrored(newConfig)) {
+					ctrl.ReconcileAllPods(newConfig)
+				} else {
+					ctrl.Enqueue(newConfig)
+				}
+			},
+			DeleteFunc: func(obj interface{}) {
+				log.Print("PodLabelConfig Delete Event")
+				// The object may have been deleted without the finalizer being run. Clean up anything left behind.
+				c, ok := obj.(*v1alpha1.PodLabelConfig)
+				if !ok {
+					tombstone, ok := obj.(cache.DeletedFinalStateUnknown)
+					if !ok {
+						return
+					}
+					if c, ok = tombstone.Obj.(*v1alpha1.PodLabelConfig); !ok {
+						return
+					}
+				}
+				if err := ctrl.enqueuePods(c); err != nil {
+					log.Printf("Error reconciling pods for plc: %s", err)
+				}
+				// The config is gone so there is no status left to update, the worker only resolves the conflicts
+				// of the others
+				ctrl.Enqueue(c)
+			},
+		},
+	)
+}
+
+// Enqueue adds the key of a PodLabelConfig to the config queue. It waits for the coalesce window like
+// the pods queued before it, so they reach the pod queue first
+func (ctrl *Controller) Enqueue(c *v1alpha1.PodLabelConfig) {
+	key, err := cache.MetaNamespaceKeyFunc(c)
+	if err == nil {
+		ctrl.Queue.AddAfter(key, ctrl.CoalesceWindow)
+	}
+}
+
+// EnqueueNamespace adds every PodLabelConfig in a namespace to the config queue, so their conflicts are
+// checked again
+func (ctrl *Controller) EnqueueNamespace(namespace string) {
+	configs, err := ctrl.Lister.PodLabelConfigs(namespace).List(labels.Everything())
+	if err != nil {
+		log.Printf("Error listing PodLabelConfigs: %s", err)
+		return
+	}
+	for _, c := range configs {
+		ctrl.Enqueue(c)
+	}
+}
+
+// Sync writes the finalizer, conflicts and status of a PodLabelConfig, or finalizes it once it is deleted
+func (ctrl *Controller) Sync(key interface{}) error {
+	namespace, name, err := cache.SplitMetaNamespaceKey(key.(string))
+	if err != nil {
+		return err
+	}
+
+	c, err := ctrl.Lister.PodLabelConfigs(namespace).Get(name)
+	if errors.IsNotFound(err) {
+		// Keys the deleted config won may now conflict between the others
+		ctrl.UpdateConflicts(namespace)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if c.GetDeletionTimestamp() != nil {
+		ctrl.UpdateConflicts(namespace)
+		ctrl.finalize(c)
+		return nil
+	}
+
+	if err := ctrl.Configs.Add(c); err != nil {
+		return err
+	}
+	ctrl.UpdateConflicts(namespace)
+	return ctrl.updateStatus(c)
+}
+
+// ReconcileAllPods queues every pod selected by a PodLabelConfig, followed by the config itself.
+// Its status is written by the config worker once the pods are processed, so the pod counts are current.
+func (ctrl *Controller) ReconcileAllPods(c *v1alpha1.PodLabelConfig) {
+	// Only reconcile after initial sync
+	if !ctrl.HasSynced() {
+		return
+	}
+
+	if err := ctrl.enqueuePods(c); err != nil {
+		log.Printf("Error reconciling pods for plc: %s", err)
+	}
+	ctrl.Enqueue(c)
+}
+
+// enqueuePods queues every pod selected by a PodLabelConfig without touching its status
+func (ctrl *Controller) enqueuePods(c *v1alpha1.PodLabelConfig) error {
+	selector, err := selectors.Pods(&c.Spec)
+	if err != nil {
+		return fmt.Errorf("invalid podSelector: %s", err)
+	}
+
+	log.Printf("Queueing all pods for plc: %s selector: %q\n", c.GetNamespace(), selector.String())
+	ctrl.EnqueuePods(c.GetNamespace(), selector)
+	return nil
+}
+
+// countPods returns the number of cached pods selected by a PodLabelConfig
+// and how many of them carry keys set by it
+func (ctrl *Controller) countPods(c *v1alpha1.PodLabelConfig) (int, int) {
+	selector, err := selectors.Pods(&c.Spec)
+	if err != nil {
+		return 0, 0
+	}
+
+	matched, patched := 0, 0
+	for _, pod := range ctrl.Pods(c.GetNamespace()) {
+		if !selector.Matches(labels.Set(pod.GetLabels())) || ctrl.IsExcluded(pod) {
+			continue
+		}
+		matched++
+		if labeling.HasKeysFrom(pod, c.GetName()) {
+			patched++
+		}
+	}
+	return matched, patched
+}
+
+// updateStatus records the result of reconciling the pods of a PodLabelConfig in its status.
+// Nothing is written unless the generation, the error or the pod counts changed, so the status updates
+// queueing the config again settle after one round.
+func (ctrl *Controller) updateStatus(c *v1alpha1.PodLabelConfig) error {
+	// Invalid selectors and templates are skipped on every pod, report them on the config
+	var reconcileErr error
+	if _, err := selectors.Pods(&c.Spec); err != nil {
+		reconcileErr = fmt.Errorf("invalid podSelector: %s", err)
+	} else if err := templates.Validate(c.Spec.Labels); err != nil {
+		reconcileErr = err
+	}
+	if reconcileErr != nil {
+		log.Printf("PodLabelConfig %s/%s: %s", c.GetNamespace(), c.GetName(), reconcileErr)
+	}
+
+	matched, patched := ctrl.countPods(c)
+	if c.Status.ObservedGeneration != c.GetGeneration() || isErrored(c) != (reconcileErr != nil) {
+		return ctrl.updateReconcileStatus(c, matched, patched, reconcileErr)
+	}
+
+	if c.Status.MatchedPods == int32(matched) && c.Status.PatchedPods == int32(patched) {
+		return nil
+	}
+	return ctrl.UpdateStatus(c, func(status *v1alpha1.PodLabelConfigStatus) {
+		status.MatchedPods = int32(matched)
+		status.PatchedPods = int32(patched)
+	})
+}
+
+// updateReconcileStatus records the result of reconciling all pods of a config in its status
+func (ctrl *Controller) updateReconcileStatus(c *v1alpha1.PodLabelConfig, matched, patched int, reconcileErr error) error {
+	ready := v1alpha1.PodLabelConfigCondition{
+		Type:   v1alpha1.PodLabelConfigReady,
+		Status: corev1.ConditionTrue,
+		Reason: "Reconciled",
+	}
+	errored := v1alpha1.PodLabelConfigCondition{
+		Type:   v1alpha1.PodLabelConfigError,
+		Status: corev1.ConditionFalse,
+		Reason: "NoError",
+	}
+	if reconcileErr != nil {
+		ready.Status = corev1.ConditionFalse
+		ready.Reason = "ReconcileFailed"
+		errored.Status = corev1.ConditionTrue
+		errored.Reason = "ReconcileFailed"
+		errored.Message = reconcileErr.Error()
+	}
+
+	now := metav1.Now()
+	return ctrl.UpdateStatus(c, func(status *v1alpha1.PodLabelConfigStatus) {
+		status.ObservedGeneration = c.GetGeneration()
+		status.MatchedPods = int32(matched)
+		status.PatchedPods = int32(patched)
+		status.LastReconcileTime = &now
+		status.Conditions = setCondition(status.Conditions, ready)
+		status.Conditions = setCondition(status.Conditions, errored)
+	})
+}
+
+// finalize removes the labels and annotations set by a deleted PodLabelConfig from its pods.
+// The pods are cleaned up by the queue workers. The finalizer is only removed once no cached pod has keys
+// from the config left, until then this is checked again on every resync.
+func (ctrl *Controller) finalize(c *v1alpha1.PodLabelConfig) {
+	// Only finalize after initial sync, otherwise labels from configs not yet in the store would be removed
+	if !ctrl.HasSynced() || !finalizers.Has(c, ctrl.Configs.Finalizer) {
+		return
+	}
+
+	log.Printf("Finalizing PodLabelConfig %s/%s", c.GetNamespace(), c.GetName())
+
+	remaining := ctrl.EnqueueOwned(c.GetNamespace(), c.GetName())
+	if remaining > 0 {
+		log.Printf("Waiting for %d pods and workloads to be cleaned up before finalizing PodLabelConfig %s/%s", remaining, c.GetNamespace(), c.GetName())
+		return
+	}
+
+	ctrl.Configs.Remove(c)
+}
+
+// UpdateConflicts sets the Conflicting condition on every PodLabelConfig in a namespace.
+// ClusterPodLabelConfigs selecting the namespace are taken into account but have no status of their own.
+func (ctrl *Controller) UpdateConflicts(namespace string) {
+	// Only update after initial sync so all configs are known
+	if !ctrl.HasSynced() {
+		return
+	}
+
+	namespaceConfigs, err := ctrl.Lister.PodLabelConfigs(namespace).List(labels.Everything())
+	if err != nil {
+		log.Printf("Error listing PodLabelConfigs: %s", err)
+		return
+	}
+	clusterConfigs, err := ctrl.ClusterLister.List(labels.Everything())
+	if err != nil {
+		log.Printf("Error listing ClusterPodLabelConfigs: %s", err)
+		return
+	}
+
+	configs := []*v1alpha1.PodLabelConfig{}
+	sources := []labeling.Source{}
+	for _, c := range namespaceConfigs {
+		if c.GetDeletionTimestamp() == nil {
+			configs = append(configs, c)
+			sources = append(sources, labeling.NamespacedSource(c))
+		}
+	}
+	for _, c := range clusterConfigs {
+		if c.GetDeletionTimestamp() == nil && selectors.ClusterConfigMatches(ctrl.NamespaceLister, c, namespace) {
+			sources = append(sources, labeling.ClusterSource(c))
+		}
+	}
+
+	for _, c := range configs {
+		condition := v1alpha1.PodLabelConfigCondition{
+			Type:   v1alpha1.PodLabelConfigConflicting,
+			Status: corev1.ConditionFalse,
+			Reason: "NoConflicts",
+		}
+
+		conflicts := labeling.FindConflicts(labeling.NamespacedSource(c), sources)
+		if len(conflicts) > 0 {
+			log.Printf("PodLabelConfig %s/%s has conflicts: %s", c.GetNamespace(), c.GetName(), strings.Join(conflicts, ", "))
+			condition.Status = corev1.ConditionTrue
+			condition.Reason = "KeyConflict"
+			condition.Message = "Overridden by configs with precedence: " + strings.Join(conflicts, ", ")
+		}
+
+		if err := ctrl.UpdateCondition(c, condition); err != nil {
+			log.Printf("Error updating conditions for PodLabelConfig %s/%s: %s", c.GetNamespace(), c.GetName(), err)
+		}
+	}
+}
+
+// UpdateCondition sets a condition on a PodLabelConfig. The update is skipped if nothing would change.
+func (ctrl *Controller) UpdateCondition(c *v1alpha1.PodLabelConfig, condition v1alpha1.PodLabelConfigCondition) error {
+	if existing := getCondition(c.Status.Conditions, condition.Type); existing != nil &&
+		existing.Status == condition.Status &&
+		existing.Reason == condition.Reason &&
+		existing.Message == condition.Message {
+		return nil
+	}
+
+	return ctrl.UpdateStatus(c, func(status *v1alpha1.PodLabelConfigStatus) {
+		status.Conditions = setCondition(status.Conditions, condition)
+	})
+}
+
+// UpdateStatus applies a change to the latest status of a PodLabelConfig and writes it using the status subresource
+func (ctrl *Controller) UpdateStatus(c *v1alpha1.PodLabelConfig, update func(*v1alpha1.PodLabelConfigStatus)) error {
+	configClient := ctrl.Client.PodlabelerV1alpha1().PodLabelConfigs(c.GetNamespace())
+
+	return ctrl.Configs.Modify(c, func(obj metav1.Object) {
+		update(&obj.(*v1alpha1.PodLabelConfig).Status)
+	}, func(obj metav1.Object) error {
+		_, err := configClient.UpdateStatus(obj.(*v1alpha1.PodLabelConfig))
+		return err
+	})
+}
+
+// getCondition returns the condition of the given type or nil if it is not set
+func getCondition(conditions []v1alpha1.PodLabelConfigCondition, t v1alpha1.PodLabelConfigConditionType) *v1alpha1.PodLabelConfigCondition {
+	for i := range conditions {
+		if conditions[i].Type == t {
+			return &conditions[i]
+		}
+	}
+	return nil
+}
+
+// setCondition adds or replaces the condition of the same type. The transition time is only moved when the status changes.
+func setCondition(conditions []v1alpha1.PodLabelConfigCondition, condition v1alpha1.PodLabelConfigCondition) []v1alpha1.PodLabelConfigCondition {
+	condition.LastTransitionTime = metav1.Now()
+	if existing := getCondition(conditions, condition.Type); existing != nil {
+		if existing.Status == condition.Status {
+			condition.LastTransitionTime = existing.LastTransitionTime
+		}
+		*existing = condition
+		return conditions
+	}
+	return append(conditions, condition)
+}
+
+// isErrored returns true if the last reconcile of the config failed
+func isErrored(c *v1alpha1.PodLabelConfig) bool {
+	condition := getCondition(c.Status.Conditions, v1alpha1.PodLabelConfigError)
+	return condition != nil && condition.Status == corev1.ConditionTrue
+}
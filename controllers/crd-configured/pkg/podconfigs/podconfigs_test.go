@@ -0,0 +1,166 @@
+package podconfigs
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/carsonoid/kube-crds-and-controllers/controllers/crd-configured/pkg/apis/podlabeler/v1alpha1"
+	"github.com/carsonoid/kube-crds-and-controllers/controllers/crd-configured/pkg/client/clientset/versioned/fake"
+	pllisters "github.com/carsonoid/kube-crds-and-controllers/controllers/crd-configured/pkg/client/listers/podlabeler/v1alpha1"
+	"github.com/carsonoid/kube-crds-and-controllers/controllers/crd-configured/pkg/finalizers"
+)
+
+const testFinalizer = "podlabelconfig.finalizers.k8s.carsonoid.net"
+
+func TestSetCondition(t *testing.T) {
+	before := metav1.NewTime(time.Now().Add(-time.Hour))
+
+	tests := []struct {
+		name      string
+		existing  []v1alpha1.PodLabelConfigCondition
+		condition v1alpha1.PodLabelConfigCondition
+		// moved is true if the transition time is expected to change
+		moved bool
+	}{
+		{
+			name:      "new condition",
+			condition: v1alpha1.PodLabelConfigCondition{Type: v1alpha1.PodLabelConfigReady, Status: corev1.ConditionTrue},
+			moved:     true,
+		},
+		{
+			name: "same status",
+			existing: []v1alpha1.PodLabelConfigCondition{
+				{Type: v1alpha1.PodLabelConfigReady, Status: corev1.ConditionTrue, Reason: "Old", LastTransitionTime: before},
+			},
+			condition: v1alpha1.PodLabelConfigCondition{Type: v1alpha1.PodLabelConfigReady, Status: corev1.ConditionTrue, Reason: "Reconciled"},
+		},
+		{
+			name: "changed status",
+			existing: []v1alpha1.PodLabelConfigCondition{
+				{Type: v1alpha1.PodLabelConfigReady, Status: corev1.ConditionFalse, LastTransitionTime: before},
+			},
+			condition: v1alpha1.PodLabelConfigCondition{Type: v1alpha1.PodLabelConfigReady, Status: corev1.ConditionTrue},
+			moved:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conditions := setCondition(tt.existing, tt.condition)
+			if len(conditions) != 1 {
+				t.Fatalf("got %d conditions, want 1", len(conditions))
+			}
+			got := conditions[0]
+			if got.Reason != tt.condition.Reason || got.Status != tt.condition.Status {
+				t.Errorf("got condition %+v, want %+v", got, tt.condition)
+			}
+			if moved := !got.LastTransitionTime.Equal(&before); moved != tt.moved {
+				t.Errorf("moved transition time: %t, want %t", moved, tt.moved)
+			}
+		})
+	}
+}
+
+func TestIsErrored(t *testing.T) {
+	c := &v1alpha1.PodLabelConfig{}
+	if isErrored(c) {
+		t.Errorf("config without conditions is errored")
+	}
+	c.Status.Conditions = setCondition(c.Status.Conditions, v1alpha1.PodLabelConfigCondition{Type: v1alpha1.PodLabelConfigError, Status: corev1.ConditionTrue})
+	if !isErrored(c) {
+		t.Errorf("config with an Error condition is not errored")
+	}
+}
+
+func TestSync(t *testing.T) {
+	now := metav1.Now()
+
+	tests := []struct {
+		name       string
+		deleted    bool
+		finalizers []string
+		owned      int
+		// want are the finalizers written, nil expects no write
+		want []string
+	}{
+		{
+			name: "new config",
+			want: []string{testFinalizer},
+		},
+		{
+			name:       "finalizer present",
+			finalizers: []string{testFinalizer},
+		},
+		{
+			name:       "deleted config with keys left",
+			deleted:    true,
+			finalizers: []string{testFinalizer, "other"},
+			owned:      2,
+		},
+		{
+			name:       "deleted config without keys left",
+			deleted:    true,
+			finalizers: []string{testFinalizer, "other"},
+			want:       []string{"other"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &v1alpha1.PodLabelConfig{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "contacts", Finalizers: tt.finalizers}}
+			c.Spec.Labels = map[string]string{"team": "contacts"}
+			if tt.deleted {
+				c.SetDeletionTimestamp(&now)
+			}
+
+			configs := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+			configs.Add(c)
+			client := fake.NewSimpleClientset(c)
+
+			var written []string
+			ctrl := &Controller{
+				Lister:          pllisters.NewPodLabelConfigLister(configs),
+				Client:          client,
+				ClusterLister:   pllisters.NewClusterPodLabelConfigLister(cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})),
+				NamespaceLister: corelisters.NewNamespaceLister(cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})),
+				Configs: finalizers.Kind{
+					Resource:  v1alpha1.Resource("podlabelconfigs"),
+					Finalizer: testFinalizer,
+					Indexer:   configs,
+					Update: func(obj metav1.Object) error {
+						written = obj.GetFinalizers()
+						return nil
+					},
+				},
+				HasSynced:    func() bool { return true },
+				EnqueuePods:  func(namespace string, selector labels.Selector) {},
+				EnqueueOwned: func(namespace, owner string) int { return tt.owned },
+				Pods:         func(namespace string) []*corev1.Pod { return nil },
+				IsExcluded:   func(pod *corev1.Pod) bool { return false },
+			}
+
+			if err := ctrl.Sync("default/contacts"); err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if !reflect.DeepEqual(written, tt.want) {
+				t.Errorf("wrote finalizers %v, want %v", written, tt.want)
+			}
+
+			// Deleted configs have no status left to update
+			status, err := client.PodlabelerV1alpha1().PodLabelConfigs("default").Get("contacts", metav1.GetOptions{})
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if got := getCondition(status.Status.Conditions, v1alpha1.PodLabelConfigConflicting) != nil; got == tt.deleted {
+				t.Errorf("wrote the Conflicting condition: %t, want %t", got, !tt.deleted)
+			}
+		})
+	}
+}
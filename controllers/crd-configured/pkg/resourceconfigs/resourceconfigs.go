@@ -0,0 +1,204 @@
+// Package resourceconfigs runs the controller for ResourceLabelConfigs.
+//
+// The controller starts a watch for the resource of every config and queues its objects, which are labelled
+// by the resource workers. The finalizer of a deleted config is only removed once no object has keys set by it
+// anymore.
+package resourceconfigs
+
+import (
+	"fmt"
+	"log"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/workqueue"
+
+	"github.com/carsonoid/kube-crds-and-controllers/controllers/crd-configured/pkg/apis/podlabeler/v1alpha1"
+	pllisters "github.com/carsonoid/kube-crds-and-controllers/controllers/crd-configured/pkg/client/listers/podlabeler/v1alpha1"
+	"github.com/carsonoid/kube-crds-and-controllers/controllers/crd-configured/pkg/finalizers"
+	"github.com/carsonoid/kube-crds-and-controllers/controllers/crd-configured/pkg/labeling"
+	"github.com/carsonoid/kube-crds-and-controllers/controllers/crd-configured/pkg/resources"
+)
+
+// Controller writes the finalizers of ResourceLabelConfigs and watches their resources
+type Controller struct {
+	Lister   pllisters.ResourceLabelConfigLister
+	Informer cache.SharedIndexInformer
+	Recorder record.EventRecorder
+
+	// Configs adds and removes the finalizer of the configs
+	Configs finalizers.Kind
+	// Watches holds the watches of the configured resources
+	Watches *resources.Watches
+
+	// HasSynced reports if the initial sync is complete. Nothing is finalized before it
+	HasSynced func() bool
+
+	// Queue holds the names of the configs to sync, and the resources no config names anymore. It is created by Start
+	Queue workqueue.RateLimitingInterface
+
+	stopCh <-chan struct{}
+}
+
+// Start creates the queue and registers the ResourceLabelConfig event handlers on the shared informer. The informer
+// itself is started with the other informers of its factory, the watches of the configured resources are started by
+// the worker syncing the queue and stopped with stopCh.
+func (ctrl *Controller) Start(stopCh <-chan struct{}) {
+	log.Print("Starting ResourceLabelConfig Controller")
+
+	ctrl.stopCh = stopCh
+	// The queue is named so its metrics can be told apart
+	ctrl.Queue = workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "resourceConfigQueue")
+
+	// The informer resyncs so that failed finalizations and watches are retried
+	ctrl.Informer.AddEventHandler(
+		cache.ResourceEventHandlerFuncs{
+			AddFunc: func(obj interface{}) {
+				log.Print("ResourceLabelConfig Add Event")
+				c := obj.(*v1alpha1.ResourceLabelConfig)
+				ctrl.Queue.Add(c.GetName())
+			},
+			UpdateFunc: func(oldobj interface{}, newobj interface{}) {
+				log.Print("ResourceLabelConfig Update Event")
+				oldConfig := oldobj.(*v1alpha1.ResourceLabelConfig)
+				newConfig := newobj.(*v1alpha1.ResourceLabelConfig)
+
+				// Configs set for deletion get their keys removed from all objects by the finalizer
+				if newConfig.GetDeletionTimestamp() != nil {
+					ctrl.Queue.Add(newConfig.GetName())
+					return
+				}
+
+				if !equality.Semantic.DeepEqual(oldConfig.Spec, newConfig.Spec) {
+					ctrl.Queue.Add(newConfig.GetName())
+
+					// Objects of a resource the config no longer names need their keys removed
+					if oldGVR, err := resources.GVR(oldConfig); err == nil {
+						if newGVR, _ := resources.GVR(newConfig); newGVR != oldGVR {
+							ctrl.Queue.Add(oldGVR)
+						}
+					}
+					return
+				}
+
+				// Retry finalizers and watches that could not be set up, for example because a CRD was not installed yet
+				gvr, err := resources.GVR(newConfig)
+				if !finalizers.Has(newConfig, ctrl.Configs.Finalizer) || (err == nil && ctrl.Watches.Get(gvr) == nil) {
+					ctrl.Queue.Add(newConfig.GetName())
+				}
+			},
+			DeleteFunc: func(obj interface{}) {
+				log.Print("ResourceLabelConfig Delete Event")
+				// The object may have been deleted without the finalizer being run. Clean up anything left behind.
+				c, ok := obj.(*v1alpha1.ResourceLabelConfig)
+				if !ok {
+					tombstone, ok := obj.(cache.DeletedFinalStateUnknown)
+					if !ok {
+						return
+					}
+					if c, ok = tombstone.Obj.(*v1alpha1.ResourceLabelConfig); !ok {
+						return
+					}
+				}
+				if gvr, err := resources.GVR(c); err == nil {
+					ctrl.Queue.Add(gvr)
+				}
+			},
+		},
+	)
+}
+
+// Sync adds the finalizer to a ResourceLabelConfig and queues the objects of its resource, or finalizes it once it
+// is deleted. Resources are queued by themselves once no config names them anymore, their objects still need the
+// keys of the config removed.
+func (ctrl *Controller) Sync(key interface{}) error {
+	if gvr, ok := key.(schema.GroupVersionResource); ok {
+		w, err := ctrl.Watches.Start(gvr, ctrl.stopCh)
+		// Nothing can be labelled on a resource that is gone
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		log.Printf("Queueing all %s", gvr.String())
+		w.EnqueueAll(corev1.NamespaceAll)
+		return nil
+	}
+
+	c, err := ctrl.Lister.Get(key.(string))
+	if errors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if c.GetDeletionTimestamp() != nil {
+		return ctrl.finalize(c)
+	}
+	if err := ctrl.Configs.Add(c); err != nil {
+		return err
+	}
+	ctrl.ReconcileAll(c)
+	return nil
+}
+
+// ReconcileAll starts watching the resource of a ResourceLabelConfig and queues all of its objects.
+// Objects the config does not select are queued as well, so keys it set before are removed.
+func (ctrl *Controller) ReconcileAll(c *v1alpha1.ResourceLabelConfig) {
+	gvr, err := resources.GVR(c)
+	if err != nil {
+		log.Printf("Invalid ResourceLabelConfig %s: %s", c.GetName(), err)
+		ctrl.Recorder.Eventf(c, corev1.EventTypeWarning, "InvalidResource", "Invalid resource: %s", err)
+		return
+	}
+
+	w, err := ctrl.Watches.Start(gvr, ctrl.stopCh)
+	if err != nil {
+		log.Printf("Error watching %s for ResourceLabelConfig %s: %s", gvr.String(), c.GetName(), err)
+		ctrl.Recorder.Eventf(c, corev1.EventTypeWarning, "InvalidResource", "Error watching %s: %s", gvr.String(), err)
+		return
+	}
+
+	// A new watch queues all objects once it has listed them
+	log.Printf("Queueing all %s for rlc: %s", gvr.String(), c.GetName())
+	w.EnqueueAll(corev1.NamespaceAll)
+}
+
+// finalize removes the labels and annotations set by a deleted ResourceLabelConfig, the same way as the
+// ClusterPodLabelConfig controller does for pods
+func (ctrl *Controller) finalize(c *v1alpha1.ResourceLabelConfig) error {
+	if !ctrl.HasSynced() || !finalizers.Has(c, ctrl.Configs.Finalizer) {
+		return nil
+	}
+
+	log.Printf("Finalizing ResourceLabelConfig %s", c.GetName())
+
+	// Configs with an invalid resource never labelled anything. Neither did configs for a resource that is gone
+	remaining := 0
+	if gvr, err := resources.GVR(c); err == nil {
+		w, err := ctrl.Watches.Start(gvr, ctrl.stopCh)
+		if err != nil && !errors.IsNotFound(err) {
+			return fmt.Errorf("error watching %s for ResourceLabelConfig %s: %s", gvr.String(), c.GetName(), err)
+		}
+		if w != nil {
+			// An empty cache does not mean the keys are gone
+			if !w.Informer.HasSynced() {
+				log.Printf("Waiting for %s to sync before finalizing ResourceLabelConfig %s", gvr.String(), c.GetName())
+				return nil
+			}
+			remaining = w.EnqueueOwned(labeling.ResourceOwnerPrefix + c.GetName())
+		}
+	}
+	if remaining > 0 {
+		log.Printf("Waiting for %d objects to be cleaned up before finalizing ResourceLabelConfig %s", remaining, c.GetName())
+		return nil
+	}
+
+	return ctrl.Configs.Remove(c)
+}
@@ -0,0 +1,90 @@
+package resourceconfigs
+
+import (
+	"reflect"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
+
+	"github.com/carsonoid/kube-crds-and-controllers/controllers/crd-configured/pkg/apis/podlabeler/v1alpha1"
+	pllisters "github.com/carsonoid/kube-crds-and-controllers/controllers/crd-configured/pkg/client/listers/podlabeler/v1alpha1"
+	"github.com/carsonoid/kube-crds-and-controllers/controllers/crd-configured/pkg/finalizers"
+	"github.com/carsonoid/kube-crds-and-controllers/controllers/crd-configured/pkg/resources"
+)
+
+const testFinalizer = "resourcelabelconfig.finalizers.k8s.carsonoid.net"
+
+// Configs with an invalid resource never start a watch, so they are synced without discovery
+func TestSyncInvalidResource(t *testing.T) {
+	now := metav1.Now()
+
+	tests := []struct {
+		name       string
+		deleted    bool
+		finalizers []string
+		// want are the finalizers written, nil expects no write
+		want      []string
+		wantEvent bool
+	}{
+		{
+			name:      "new config",
+			want:      []string{testFinalizer},
+			wantEvent: true,
+		},
+		{
+			name:       "finalizer present",
+			finalizers: []string{testFinalizer},
+			wantEvent:  true,
+		},
+		{
+			name:       "deleted config",
+			deleted:    true,
+			finalizers: []string{testFinalizer},
+			want:       []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &v1alpha1.ResourceLabelConfig{ObjectMeta: metav1.ObjectMeta{Name: "pods", Finalizers: tt.finalizers}}
+			c.Spec.Version = "v1"
+			c.Spec.Resource = "pods"
+			if tt.deleted {
+				c.SetDeletionTimestamp(&now)
+			}
+
+			indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+			indexer.Add(c)
+			recorder := record.NewFakeRecorder(10)
+
+			var written []string
+			ctrl := &Controller{
+				Lister:   pllisters.NewResourceLabelConfigLister(indexer),
+				Recorder: recorder,
+				Configs: finalizers.Kind{
+					Resource:  v1alpha1.Resource("resourcelabelconfigs"),
+					Finalizer: testFinalizer,
+					Indexer:   indexer,
+					Update: func(obj metav1.Object) error {
+						written = append([]string{}, obj.GetFinalizers()...)
+						return nil
+					},
+				},
+				Watches:   resources.NewWatches(nil, nil, nil),
+				HasSynced: func() bool { return true },
+			}
+
+			if err := ctrl.Sync("pods"); err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if !reflect.DeepEqual(written, tt.want) {
+				t.Errorf("wrote finalizers %v, want %v", written, tt.want)
+			}
+			if got := len(recorder.Events) > 0; got != tt.wantEvent {
+				t.Errorf("recorded an event: %t, want %t", got, tt.wantEvent)
+			}
+		})
+	}
+}
@@ -0,0 +1,117 @@
+// Package resources labels arbitrary resources for ResourceLabelConfigs.
+//
+// Objects are watched over the dynamic client and wrapped in a pod, so they are labelled by the same merge
+// logic as pods. Changes are written back with a json merge patch, which works for any resource.
+package resources
+
+import (
+	"encoding/json"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/carsonoid/kube-crds-and-controllers/controllers/crd-configured/pkg/apis/podlabeler/v1alpha1"
+)
+
+// Key is the key of an object in the resource queue
+type Key struct {
+	GVR schema.GroupVersionResource
+	Key string
+}
+
+func (k Key) String() string {
+	return k.GVR.String() + " " + k.Key
+}
+
+// GVR returns the resource named by a ResourceLabelConfig
+func GVR(c *v1alpha1.ResourceLabelConfig) (schema.GroupVersionResource, error) {
+	gvr := schema.GroupVersionResource{Group: c.Spec.Group, Version: c.Spec.Version, Resource: c.Spec.Resource}
+	if gvr.Version == "" || gvr.Resource == "" {
+		return gvr, fmt.Errorf("version and resource are required")
+	}
+	// Both config kinds would keep rewriting the tracking annotations of a pod
+	if gvr.Group == "" && gvr.Resource == "pods" {
+		return gvr, fmt.Errorf("pods are labelled by PodLabelConfigs and ClusterPodLabelConfigs")
+	}
+	return gvr, nil
+}
+
+// Pod wraps the labels and annotations of an object in a pod, so the object can be labelled like a pod
+func Pod(obj *unstructured.Unstructured) *corev1.Pod {
+	pod := &corev1.Pod{}
+	pod.SetName(obj.GetName())
+	pod.SetNamespace(obj.GetNamespace())
+	pod.SetLabels(obj.GetLabels())
+	pod.SetAnnotations(obj.GetAnnotations())
+	return pod
+}
+
+// MetadataMergePatch returns a json merge patch with the labels and annotations that differ between two pods.
+// Removed keys are set to null
+func MetadataMergePatch(pod, newPod *corev1.Pod) ([]byte, error) {
+	diff := func(old, new map[string]string) map[string]interface{} {
+		changes := make(map[string]interface{})
+		for k, v := range new {
+			if oldVal, ok := old[k]; !ok || oldVal != v {
+				changes[k] = v
+			}
+		}
+		for k := range old {
+			if _, ok := new[k]; !ok {
+				changes[k] = nil
+			}
+		}
+		return changes
+	}
+
+	metadata := make(map[string]interface{})
+	if changes := diff(pod.GetLabels(), newPod.GetLabels()); len(changes) > 0 {
+		metadata["labels"] = changes
+	}
+	if changes := diff(pod.GetAnnotations(), newPod.GetAnnotations()); len(changes) > 0 {
+		metadata["annotations"] = changes
+	}
+	return json.Marshal(map[string]interface{}{"metadata": metadata})
+}
+
+// Discover looks up a resource with the discovery client. The dynamic client needs to know if it is namespaced
+func Discover(client discovery.DiscoveryInterface, gvr schema.GroupVersionResource) (*metav1.APIResource, error) {
+	resources, err := client.ServerResourcesForGroupVersion(gvr.GroupVersion().String())
+	if err != nil {
+		return nil, err
+	}
+	for i := range resources.APIResources {
+		if resources.APIResources[i].Name == gvr.Resource {
+			return &resources.APIResources[i], nil
+		}
+	}
+	return nil, errors.NewNotFound(gvr.GroupResource(), "")
+}
+
+// NewInformer returns an informer for a resource in all namespaces. There is no informer factory for
+// arbitrary resources, so every resource gets its own informer over the dynamic client.
+func NewInformer(client dynamic.Interface, apiResource *metav1.APIResource) cache.SharedIndexInformer {
+	resourceClient := client.Resource(apiResource, metav1.NamespaceAll)
+	return cache.NewSharedIndexInformer(
+		&cache.ListWatch{
+			ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+				return resourceClient.List(options)
+			},
+			WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+				return resourceClient.Watch(options)
+			},
+		},
+		&unstructured.Unstructured{},
+		0,
+		cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc},
+	)
+}
@@ -0,0 +1,147 @@
+package resources
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/carsonoid/kube-crds-and-controllers/controllers/crd-configured/pkg/apis/podlabeler/v1alpha1"
+)
+
+func TestGVR(t *testing.T) {
+	tests := []struct {
+		name    string
+		spec    v1alpha1.ResourceLabelConfigSpec
+		want    schema.GroupVersionResource
+		wantErr bool
+	}{
+		{
+			name: "core resource",
+			spec: v1alpha1.ResourceLabelConfigSpec{Version: "v1", Resource: "services"},
+			want: schema.GroupVersionResource{Version: "v1", Resource: "services"},
+		},
+		{
+			name: "grouped resource",
+			spec: v1alpha1.ResourceLabelConfigSpec{Group: "apps", Version: "v1beta2", Resource: "deployments"},
+			want: schema.GroupVersionResource{Group: "apps", Version: "v1beta2", Resource: "deployments"},
+		},
+		{
+			name:    "missing version",
+			spec:    v1alpha1.ResourceLabelConfigSpec{Group: "apps", Resource: "deployments"},
+			wantErr: true,
+		},
+		{
+			name:    "missing resource",
+			spec:    v1alpha1.ResourceLabelConfigSpec{Version: "v1"},
+			wantErr: true,
+		},
+		{
+			name:    "pods",
+			spec:    v1alpha1.ResourceLabelConfigSpec{Version: "v1", Resource: "pods"},
+			wantErr: true,
+		},
+		{
+			name: "pods of another group",
+			spec: v1alpha1.ResourceLabelConfigSpec{Group: "metrics.k8s.io", Version: "v1beta1", Resource: "pods"},
+			want: schema.GroupVersionResource{Group: "metrics.k8s.io", Version: "v1beta1", Resource: "pods"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := GVR(&v1alpha1.ResourceLabelConfig{Spec: tt.spec})
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("expected an error, got %s", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if got != tt.want {
+				t.Errorf("GVR() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPod(t *testing.T) {
+	obj := &unstructured.Unstructured{}
+	obj.SetName("web")
+	obj.SetNamespace("default")
+	obj.SetLabels(map[string]string{"app": "web"})
+	obj.SetAnnotations(map[string]string{"contact": "web@example.com"})
+
+	pod := Pod(obj)
+	if pod.GetName() != "web" || pod.GetNamespace() != "default" {
+		t.Errorf("pod is %s/%s, want default/web", pod.GetNamespace(), pod.GetName())
+	}
+	if !reflect.DeepEqual(pod.GetLabels(), obj.GetLabels()) || !reflect.DeepEqual(pod.GetAnnotations(), obj.GetAnnotations()) {
+		t.Errorf("pod metadata = %#v, want the labels and annotations of the object", pod.ObjectMeta)
+	}
+}
+
+func TestMetadataMergePatch(t *testing.T) {
+	pod := func(labels, annotations map[string]string) *corev1.Pod {
+		return &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Labels: labels, Annotations: annotations}}
+	}
+
+	tests := []struct {
+		name   string
+		pod    *corev1.Pod
+		newPod *corev1.Pod
+		want   string
+	}{
+		{
+			name:   "unchanged",
+			pod:    pod(map[string]string{"app": "web"}, nil),
+			newPod: pod(map[string]string{"app": "web"}, nil),
+			want:   `{"metadata":{}}`,
+		},
+		{
+			name:   "added label",
+			pod:    pod(nil, nil),
+			newPod: pod(map[string]string{"team": "web"}, nil),
+			want:   `{"metadata":{"labels":{"team":"web"}}}`,
+		},
+		{
+			name:   "changed and removed labels",
+			pod:    pod(map[string]string{"app": "web", "team": "web", "env": "dev"}, nil),
+			newPod: pod(map[string]string{"app": "web", "team": "platform"}, nil),
+			want:   `{"metadata":{"labels":{"env":null,"team":"platform"}}}`,
+		},
+		{
+			name:   "labels and annotations",
+			pod:    pod(map[string]string{"app": "web"}, map[string]string{"contact": "old"}),
+			newPod: pod(map[string]string{"app": "web", "team": "web"}, map[string]string{}),
+			want:   `{"metadata":{"annotations":{"contact":null},"labels":{"team":"web"}}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			patch, err := MetadataMergePatch(tt.pod, tt.newPod)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			// Compare the decoded patches, so the test does not depend on the key order
+			var got, want interface{}
+			if err := json.Unmarshal(patch, &got); err != nil {
+				t.Fatalf("decoding %s: %s", patch, err)
+			}
+			if err := json.Unmarshal([]byte(tt.want), &want); err != nil {
+				t.Fatalf("decoding %s: %s", tt.want, err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("MetadataMergePatch() = %s, want %s", patch, tt.want)
+			}
+		})
+	}
+}
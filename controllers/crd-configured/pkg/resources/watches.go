@@ -0,0 +1,158 @@
+package resources
+
+import (
+	"log"
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/carsonoid/kube-crds-and-controllers/controllers/crd-configured/pkg/labeling"
+)
+
+// Watch is the dynamic informer for a resource labelled by ResourceLabelConfigs
+type Watch struct {
+	GVR         schema.GroupVersionResource
+	APIResource *metav1.APIResource
+	Client      dynamic.Interface
+	Informer    cache.SharedIndexInformer
+
+	enqueue func(Key)
+}
+
+// Enqueue queues the key of an object of the resource
+func (w *Watch) Enqueue(obj interface{}) {
+	key, err := cache.MetaNamespaceKeyFunc(obj)
+	if err == nil {
+		w.enqueue(Key{GVR: w.GVR, Key: key})
+	}
+}
+
+// Objects returns the cached objects in a namespace, or in all namespaces for corev1.NamespaceAll
+func (w *Watch) Objects(namespace string) []*unstructured.Unstructured {
+	var objs []interface{}
+	if namespace == corev1.NamespaceAll {
+		objs = w.Informer.GetIndexer().List()
+	} else {
+		var err error
+		if objs, err = w.Informer.GetIndexer().ByIndex(cache.NamespaceIndex, namespace); err != nil {
+			log.Printf("Error listing %s in namespace %s: %s", w.GVR.Resource, namespace, err)
+			return nil
+		}
+	}
+
+	list := make([]*unstructured.Unstructured, 0, len(objs))
+	for _, obj := range objs {
+		list = append(list, obj.(*unstructured.Unstructured))
+	}
+	return list
+}
+
+// EnqueueAll queues every cached object in the namespace
+func (w *Watch) EnqueueAll(namespace string) {
+	for _, obj := range w.Objects(namespace) {
+		w.Enqueue(obj)
+	}
+}
+
+// EnqueueOwned queues every cached object that still has keys set by owner and returns how many were found
+func (w *Watch) EnqueueOwned(owner string) int {
+	owned := 0
+	for _, obj := range w.Objects(corev1.NamespaceAll) {
+		if labeling.HasKeysFrom(Pod(obj), owner) {
+			w.Enqueue(obj)
+			owned++
+		}
+	}
+	return owned
+}
+
+// Watches starts the watches of the resources named by ResourceLabelConfigs. Watches are kept until they
+// are stopped, the key of every added or changed object is passed to enqueue.
+type Watches struct {
+	discovery  discovery.DiscoveryInterface
+	clientPool dynamic.ClientPool
+	enqueue    func(Key)
+
+	lock    sync.Mutex
+	watches map[schema.GroupVersionResource]*Watch
+}
+
+// NewWatches returns Watches which look up resources with discovery and watch them with clients of clientPool
+func NewWatches(discovery discovery.DiscoveryInterface, clientPool dynamic.ClientPool, enqueue func(Key)) *Watches {
+	return &Watches{
+		discovery:  discovery,
+		clientPool: clientPool,
+		enqueue:    enqueue,
+		watches:    make(map[schema.GroupVersionResource]*Watch),
+	}
+}
+
+// Get returns the watch for a resource, or nil if it is not watched yet
+func (ws *Watches) Get(gvr schema.GroupVersionResource) *Watch {
+	ws.lock.Lock()
+	defer ws.lock.Unlock()
+	return ws.watches[gvr]
+}
+
+// Start returns the watch for a resource and starts it if needed. The watch runs until stopCh is closed.
+func (ws *Watches) Start(gvr schema.GroupVersionResource, stopCh <-chan struct{}) (*Watch, error) {
+	if w := ws.Get(gvr); w != nil {
+		return w, nil
+	}
+
+	// Discovery and the REST mapping of the client pool call the apiserver, the lock is not held for them
+	apiResource, err := Discover(ws.discovery, gvr)
+	if err != nil {
+		return nil, err
+	}
+	client, err := ws.clientPool.ClientForGroupVersionResource(gvr)
+	if err != nil {
+		return nil, err
+	}
+
+	informer := NewInformer(client, apiResource)
+	w := &Watch{GVR: gvr, APIResource: apiResource, Client: client, Informer: informer, enqueue: ws.enqueue}
+	informer.AddEventHandler(
+		cache.ResourceEventHandlerFuncs{
+			AddFunc: func(obj interface{}) {
+				w.Enqueue(obj)
+			},
+			UpdateFunc: func(oldobj interface{}, newobj interface{}) {
+				if oldobj.(metav1.Object).GetResourceVersion() != newobj.(metav1.Object).GetResourceVersion() {
+					w.Enqueue(newobj)
+				}
+			},
+		},
+	)
+
+	ws.lock.Lock()
+	defer ws.lock.Unlock()
+
+	// Another config may have started the same watch in the meantime, the new informer is dropped unstarted
+	if existing, ok := ws.watches[gvr]; ok {
+		return existing, nil
+	}
+
+	log.Printf("Starting watch for %s", gvr.String())
+	go informer.Run(stopCh)
+	ws.watches[gvr] = w
+	return w, nil
+}
+
+// List returns all running watches
+func (ws *Watches) List() []*Watch {
+	ws.lock.Lock()
+	defer ws.lock.Unlock()
+
+	watches := make([]*Watch, 0, len(ws.watches))
+	for _, w := range ws.watches {
+		watches = append(watches, w)
+	}
+	return watches
+}
@@ -0,0 +1,96 @@
+package resources
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/carsonoid/kube-crds-and-controllers/controllers/crd-configured/pkg/labeling"
+)
+
+// testWatch returns a watch of services with a cache holding the given objects. The keys it queues are recorded in queued
+func testWatch(queued *[]string, objs ...*unstructured.Unstructured) *Watch {
+	informer := cache.NewSharedIndexInformer(&cache.ListWatch{}, &unstructured.Unstructured{}, 0,
+		cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	for _, obj := range objs {
+		informer.GetIndexer().Add(obj)
+	}
+
+	gvr := schema.GroupVersionResource{Version: "v1", Resource: "services"}
+	return &Watch{GVR: gvr, Informer: informer, enqueue: func(key Key) {
+		if key.GVR != gvr {
+			panic("queued a key of another resource")
+		}
+		*queued = append(*queued, key.Key)
+	}}
+}
+
+func service(namespace, name, managed string) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{}
+	obj.SetNamespace(namespace)
+	obj.SetName(name)
+	if managed != "" {
+		obj.SetAnnotations(map[string]string{labeling.ManagedLabelsAnnotation: managed})
+	}
+	return obj
+}
+
+func TestWatchEnqueueAll(t *testing.T) {
+	objs := []*unstructured.Unstructured{service("default", "web", ""), service("default", "db", ""), service("other", "web", "")}
+
+	tests := []struct {
+		name      string
+		namespace string
+		want      []string
+	}{
+		{
+			name:      "namespace",
+			namespace: "default",
+			want:      []string{"default/db", "default/web"},
+		},
+		{
+			name:      "all namespaces",
+			namespace: corev1.NamespaceAll,
+			want:      []string{"default/db", "default/web", "other/web"},
+		},
+		{
+			name:      "empty namespace",
+			namespace: "empty",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var queued []string
+			testWatch(&queued, objs...).EnqueueAll(tt.namespace)
+
+			sort.Strings(queued)
+			if !reflect.DeepEqual(queued, tt.want) {
+				t.Errorf("queued %v, want %v", queued, tt.want)
+			}
+		})
+	}
+}
+
+func TestWatchEnqueueOwned(t *testing.T) {
+	var queued []string
+	w := testWatch(&queued,
+		service("default", "web", `{"ResourceLabelConfig/services":["team"]}`),
+		service("default", "db", `{"ResourceLabelConfig/other":["team"]}`),
+		service("other", "web", `{"ResourceLabelConfig/services":["team"]}`),
+		service("other", "db", ""),
+	)
+
+	if owned := w.EnqueueOwned("ResourceLabelConfig/services"); owned != 2 {
+		t.Errorf("EnqueueOwned() = %d, want 2", owned)
+	}
+	sort.Strings(queued)
+	if want := []string{"default/web", "other/web"}; !reflect.DeepEqual(queued, want) {
+		t.Errorf("queued %v, want %v", queued, want)
+	}
+}
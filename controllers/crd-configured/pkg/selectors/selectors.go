@@ -0,0 +1,65 @@
+// Package selectors decides which pods and namespaces a config targets.
+//
+// A missing selector selects everything, a config with an invalid selector never matches anything.
+package selectors
+
+import (
+	"log"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	corelisters "k8s.io/client-go/listers/core/v1"
+
+	"github.com/carsonoid/kube-crds-and-controllers/controllers/crd-configured/pkg/apis/podlabeler/v1alpha1"
+	"github.com/carsonoid/kube-crds-and-controllers/controllers/crd-configured/pkg/labeling"
+)
+
+// Pods returns the selector for the pods targeted by a config.
+// A missing podSelector selects every pod in the namespace.
+func Pods(spec *v1alpha1.PodLabelConfigSpec) (labels.Selector, error) {
+	if spec.PodSelector == nil {
+		return labels.Everything(), nil
+	}
+	return metav1.LabelSelectorAsSelector(spec.PodSelector)
+}
+
+// PodMatches checks if a set of pod labels is selected by a config.
+// Configs with an invalid selector never match.
+func PodMatches(c labeling.Source, podLabels labels.Set) bool {
+	selector, err := Pods(c.Spec)
+	if err != nil {
+		log.Printf("Invalid podSelector in %s: %s", c.Owner, err)
+		return false
+	}
+	return selector.Matches(podLabels)
+}
+
+// Namespaces returns the selector for the namespaces targeted by a config.
+// A missing namespaceSelector selects every namespace.
+func Namespaces(s *metav1.LabelSelector) (labels.Selector, error) {
+	if s == nil {
+		return labels.Everything(), nil
+	}
+	return metav1.LabelSelectorAsSelector(s)
+}
+
+// NamespaceMatches checks if a namespace is selected by the namespaceSelector of config.
+// Configs with an invalid selector never match, neither do namespaces missing from the lister.
+func NamespaceMatches(lister corelisters.NamespaceLister, s *metav1.LabelSelector, config, namespace string) bool {
+	selector, err := Namespaces(s)
+	if err != nil {
+		log.Printf("Invalid namespaceSelector in %s: %s", config, err)
+		return false
+	}
+
+	ns, err := lister.Get(namespace)
+	if err != nil {
+		return false
+	}
+	return selector.Matches(labels.Set(ns.GetLabels()))
+}
+
+// ClusterConfigMatches checks if a namespace is selected by a ClusterPodLabelConfig.
+func ClusterConfigMatches(lister corelisters.NamespaceLister, c *v1alpha1.ClusterPodLabelConfig, namespace string) bool {
+	return NamespaceMatches(lister, c.Spec.NamespaceSelector, "ClusterPodLabelConfig "+c.GetName(), namespace)
+}
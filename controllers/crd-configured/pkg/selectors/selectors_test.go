@@ -0,0 +1,100 @@
+package selectors
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/carsonoid/kube-crds-and-controllers/controllers/crd-configured/pkg/apis/podlabeler/v1alpha1"
+	"github.com/carsonoid/kube-crds-and-controllers/controllers/crd-configured/pkg/labeling"
+)
+
+var invalid = &metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "app", Operator: "Sometimes"}}}
+
+func TestPodMatches(t *testing.T) {
+	tests := []struct {
+		name     string
+		selector *metav1.LabelSelector
+		want     bool
+	}{
+		{
+			name: "missing selector",
+			want: true,
+		},
+		{
+			name:     "matching selector",
+			selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+			want:     true,
+		},
+		{
+			name:     "other selector",
+			selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "db"}},
+		},
+		{
+			name:     "invalid selector",
+			selector: invalid,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := labeling.Source{Owner: "test1", Spec: &v1alpha1.PodLabelConfigSpec{PodSelector: tt.selector}}
+			if got := PodMatches(c, labels.Set{"app": "web", "tier": "frontend"}); got != tt.want {
+				t.Errorf("PodMatches() = %t, want %t", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNamespaceMatches(t *testing.T) {
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	indexer.Add(&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "web", Labels: map[string]string{"team": "web"}}})
+	lister := corelisters.NewNamespaceLister(indexer)
+
+	tests := []struct {
+		name      string
+		selector  *metav1.LabelSelector
+		namespace string
+		want      bool
+	}{
+		{
+			name:      "missing selector",
+			namespace: "web",
+			want:      true,
+		},
+		{
+			name:      "matching selector",
+			selector:  &metav1.LabelSelector{MatchLabels: map[string]string{"team": "web"}},
+			namespace: "web",
+			want:      true,
+		},
+		{
+			name:      "other selector",
+			selector:  &metav1.LabelSelector{MatchLabels: map[string]string{"team": "db"}},
+			namespace: "web",
+		},
+		{
+			name:      "invalid selector",
+			selector:  invalid,
+			namespace: "web",
+		},
+		{
+			name:      "unknown namespace",
+			namespace: "db",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &v1alpha1.ClusterPodLabelConfig{ObjectMeta: metav1.ObjectMeta{Name: "contacts"}}
+			c.Spec.NamespaceSelector = tt.selector
+			if got := ClusterConfigMatches(lister, c, tt.namespace); got != tt.want {
+				t.Errorf("ClusterConfigMatches() = %t, want %t", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,113 @@
+// Package templates renders templated label values against the pod they are set on.
+//
+// Label values containing {{ are text/template templates executed with the pod as data, for example
+// {{ .Spec.NodeName }}. The rendered value is sanitized so it is always accepted as a label value.
+package templates
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// invalidLabelValueChars matches everything that is not allowed in a label value
+var invalidLabelValueChars = regexp.MustCompile(`[^-A-Za-z0-9_.]`)
+
+// OwnerFunc returns the kind and name of the object at the top of the controller references of a pod
+type OwnerFunc func(pod *corev1.Pod) (string, string, error)
+
+// IsTemplate reports if a label value is a template
+func IsTemplate(value string) bool {
+	return strings.Contains(value, "{{")
+}
+
+// Funcs returns the functions available to label value templates. They are evaluated lazily
+// so the owner lookups only hit the apiserver when a template uses them.
+func Funcs(pod *corev1.Pod, owner OwnerFunc) template.FuncMap {
+	return template.FuncMap{
+		"ownerKind": func() (string, error) {
+			kind, _, err := owner(pod)
+			return kind, err
+		},
+		"ownerName": func() (string, error) {
+			_, name, err := owner(pod)
+			return name, err
+		},
+		"imageTag": func() string {
+			if len(pod.Spec.Containers) == 0 {
+				return ""
+			}
+			return ImageTag(pod.Spec.Containers[0].Image)
+		},
+	}
+}
+
+// Parse parses a label value template. Parsing only checks that the funcs exist, so pod and owner may be nil
+// when the template is not executed.
+func Parse(pod *corev1.Pod, owner OwnerFunc, value string) (*template.Template, error) {
+	return template.New("value").Option("missingkey=error").Funcs(Funcs(pod, owner)).Parse(value)
+}
+
+// Validate returns an error for the first label value which is not a valid template
+func Validate(labels map[string]string) error {
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		if _, err := Parse(nil, nil, labels[k]); err != nil {
+			return fmt.Errorf("invalid template for label %s: %s", k, err)
+		}
+	}
+	return nil
+}
+
+// Render evaluates a templated label value against a pod. The result is sanitized into a valid
+// label value. Values without a template are returned as is.
+func Render(pod *corev1.Pod, owner OwnerFunc, value string) (string, error) {
+	if !IsTemplate(value) {
+		return value, nil
+	}
+
+	t, err := Parse(pod, owner, value)
+	if err != nil {
+		return "", err
+	}
+
+	var b bytes.Buffer
+	if err := t.Execute(&b, pod); err != nil {
+		return "", err
+	}
+	return SanitizeLabelValue(b.String()), nil
+}
+
+// SanitizeLabelValue replaces invalid characters and trims the value so it is accepted as a label value
+func SanitizeLabelValue(value string) string {
+	value = invalidLabelValueChars.ReplaceAllString(value, "-")
+	if len(value) > validation.LabelValueMaxLength {
+		value = value[:validation.LabelValueMaxLength]
+	}
+	// values must begin and end with an alphanumeric character
+	return strings.Trim(value, "-_.")
+}
+
+// ImageTag returns the tag of a container image, images without a tag use latest
+func ImageTag(image string) string {
+	// drop the digest
+	if i := strings.Index(image, "@"); i >= 0 {
+		image = image[:i]
+	}
+	// the tag follows the last colon, unless that colon belongs to a registry port
+	if i := strings.LastIndex(image, ":"); i >= 0 && i > strings.LastIndex(image, "/") {
+		return image[i+1:]
+	}
+	return "latest"
+}
@@ -0,0 +1,144 @@
+package templates
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestSanitizeLabelValue(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		want  string
+	}{
+		{name: "valid", value: "web-1.prod_a", want: "web-1.prod_a"},
+		{name: "empty", value: "", want: ""},
+		{name: "invalid characters", value: "node/1:a b", want: "node-1-a-b"},
+		{name: "trim non alphanumeric ends", value: "_-web.-", want: "web"},
+		{name: "only invalid characters", value: "///", want: ""},
+		{name: "too long", value: strings.Repeat("a", 70), want: strings.Repeat("a", 63)},
+		{name: "trimmed after truncating", value: strings.Repeat("a", 62) + "-b", want: strings.Repeat("a", 62)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SanitizeLabelValue(tt.value); got != tt.want {
+				t.Errorf("SanitizeLabelValue(%q) = %q, want %q", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestImageTag(t *testing.T) {
+	tests := []struct {
+		image string
+		want  string
+	}{
+		{image: "nginx", want: "latest"},
+		{image: "nginx:1.13", want: "1.13"},
+		{image: "library/nginx:1.13-alpine", want: "1.13-alpine"},
+		{image: "registry.example.com:5000/nginx", want: "latest"},
+		{image: "registry.example.com:5000/nginx:1.13", want: "1.13"},
+		{image: "nginx@sha256:0123abcd", want: "latest"},
+		{image: "nginx:1.13@sha256:0123abcd", want: "1.13"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.image, func(t *testing.T) {
+			if got := ImageTag(tt.image); got != tt.want {
+				t.Errorf("ImageTag(%q) = %q, want %q", tt.image, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRender(t *testing.T) {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "web-1", Namespace: "default"},
+		Spec: corev1.PodSpec{
+			NodeName:   "node-1.example.com",
+			Containers: []corev1.Container{{Name: "web", Image: "nginx:1.13"}},
+		},
+	}
+	owner := func(p *corev1.Pod) (string, string, error) {
+		if p != pod {
+			t.Errorf("owner looked up for %s", p.GetName())
+		}
+		return "Deployment", "web", nil
+	}
+	failingOwner := func(p *corev1.Pod) (string, string, error) {
+		return "", "", fmt.Errorf("replicaset web-1234 not found")
+	}
+
+	tests := []struct {
+		name    string
+		value   string
+		owner   OwnerFunc
+		want    string
+		wantErr bool
+	}{
+		{name: "plain value", value: "web", owner: owner, want: "web"},
+		{name: "pod field", value: "{{ .Spec.NodeName }}", owner: owner, want: "node-1.example.com"},
+		{name: "sanitized", value: "{{ .Namespace }}/{{ .Name }}", owner: owner, want: "default-web-1"},
+		{name: "owner kind", value: "{{ ownerKind }}", owner: owner, want: "Deployment"},
+		{name: "owner name", value: "{{ ownerKind }}-{{ ownerName }}", owner: owner, want: "Deployment-web"},
+		{name: "image tag", value: "{{ imageTag }}", owner: owner, want: "1.13"},
+		{name: "owner lookup fails", value: "{{ ownerName }}", owner: failingOwner, wantErr: true},
+		{name: "unknown field", value: "{{ .Spec.Missing }}", owner: owner, wantErr: true},
+		{name: "unknown function", value: "{{ missing }}", owner: owner, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Render(pod, tt.owner, tt.value)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("expected an error, got %q", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if got != tt.want {
+				t.Errorf("Render(%q) = %q, want %q", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderWithoutContainers(t *testing.T) {
+	got, err := Render(&corev1.Pod{}, nil, "{{ imageTag }}")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if got != "" {
+		t.Errorf("Render() = %q, want an empty value", got)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		labels  map[string]string
+		wantErr bool
+	}{
+		{name: "no labels", labels: nil},
+		{name: "plain values", labels: map[string]string{"team": "web"}},
+		{name: "templates", labels: map[string]string{"node": "{{ .Spec.NodeName }}", "owner": "{{ ownerName }}"}},
+		{name: "unknown function", labels: map[string]string{"team": "web", "owner": "{{ owner }}"}, wantErr: true},
+		{name: "unclosed action", labels: map[string]string{"node": "{{ .Spec.NodeName"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := Validate(tt.labels); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, want error %t", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,162 @@
+package webhook
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// AdmitFunc handles a single admission request and returns the response to send back
+type AdmitFunc func(*AdmissionRequest) *AdmissionResponse
+
+// Serve returns an http handler which decodes an AdmissionReview, passes the request to admit
+// and writes the response back to the apiserver
+func Serve(admit AdmitFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if contentType := r.Header.Get("Content-Type"); contentType != "application/json" {
+			http.Error(w, fmt.Sprintf("invalid Content-Type %q, expected application/json", contentType), http.StatusUnsupportedMediaType)
+			return
+		}
+
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		review := AdmissionReview{}
+		if err := json.Unmarshal(body, &review); err != nil || review.Request == nil {
+			http.Error(w, fmt.Sprintf("could not decode AdmissionReview: %v", err), http.StatusBadRequest)
+			return
+		}
+
+		response := admit(review.Request)
+		response.UID = review.Request.UID
+
+		// The response is sent back in the same AdmissionReview, without the request
+		review.Request = nil
+		review.Response = response
+
+		data, err := json.Marshal(review)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if _, err := w.Write(data); err != nil {
+			log.Printf("Error writing AdmissionReview response: %s", err)
+		}
+	}
+}
+
+// ConvertFunc converts a single raw object to the given apiVersion and returns the converted object
+type ConvertFunc func(object runtime.RawExtension, desiredAPIVersion string) (runtime.RawExtension, error)
+
+// ServeConversion returns an http handler which decodes a ConversionReview, passes every object to
+// convert and writes the converted objects back to the apiserver. A single failure fails the whole review
+func ServeConversion(convert ConvertFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if contentType := r.Header.Get("Content-Type"); contentType != "application/json" {
+			http.Error(w, fmt.Sprintf("invalid Content-Type %q, expected application/json", contentType), http.StatusUnsupportedMediaType)
+			return
+		}
+
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		review := ConversionReview{}
+		if err := json.Unmarshal(body, &review); err != nil || review.Request == nil {
+			http.Error(w, fmt.Sprintf("could not decode ConversionReview: %v", err), http.StatusBadRequest)
+			return
+		}
+
+		response := &ConversionResponse{
+			UID:    review.Request.UID,
+			Result: metav1.Status{Status: metav1.StatusSuccess},
+		}
+		for _, object := range review.Request.Objects {
+			converted, err := convert(object, review.Request.DesiredAPIVersion)
+			if err != nil {
+				response.ConvertedObjects = nil
+				response.Result = metav1.Status{
+					Status:  metav1.StatusFailure,
+					Message: err.Error(),
+				}
+				break
+			}
+			response.ConvertedObjects = append(response.ConvertedObjects, converted)
+		}
+
+		review.Request = nil
+		review.Response = response
+
+		data, err := json.Marshal(review)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if _, err := w.Write(data); err != nil {
+			log.Printf("Error writing ConversionReview response: %s", err)
+		}
+	}
+}
+
+// Allowed returns a response which admits the request unchanged
+func Allowed() *AdmissionResponse {
+	return &AdmissionResponse{Allowed: true}
+}
+
+// Denied returns a response which rejects the request with the given message
+func Denied(code int32, message string) *AdmissionResponse {
+	return &AdmissionResponse{
+		Allowed: false,
+		Result: &metav1.Status{
+			Status:  metav1.StatusFailure,
+			Code:    code,
+			Message: message,
+		},
+	}
+}
+
+// Patched returns a response which admits the request with the given json patch applied
+func Patched(ops []JSONPatchOp) (*AdmissionResponse, error) {
+	response := Allowed()
+	if len(ops) == 0 {
+		return response, nil
+	}
+
+	patch, err := json.Marshal(ops)
+	if err != nil {
+		return nil, err
+	}
+
+	patchType := PatchTypeJSONPatch
+	response.Patch = patch
+	response.PatchType = &patchType
+	return response, nil
+}
+
+// MetadataPatch returns the json patch operations which turn the labels and annotations of obj into those of newObj.
+// The whole maps are replaced, "add" overwrites a map that is already present.
+func MetadataPatch(obj, newObj metav1.Object) []JSONPatchOp {
+	ops := []JSONPatchOp{}
+	if !equality.Semantic.DeepEqual(obj.GetLabels(), newObj.GetLabels()) {
+		ops = append(ops, JSONPatchOp{Op: "add", Path: "/metadata/labels", Value: newObj.GetLabels()})
+	}
+	if !equality.Semantic.DeepEqual(obj.GetAnnotations(), newObj.GetAnnotations()) {
+		ops = append(ops, JSONPatchOp{Op: "add", Path: "/metadata/annotations", Value: newObj.GetAnnotations()})
+	}
+	return ops
+}
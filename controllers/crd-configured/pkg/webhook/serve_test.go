@@ -0,0 +1,193 @@
+package webhook
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/carsonoid/kube-crds-and-controllers/controllers/crd-configured/pkg/apis/podlabeler/v1alpha1"
+	"github.com/carsonoid/kube-crds-and-controllers/controllers/crd-configured/pkg/apis/podlabeler/v1beta1"
+	"github.com/carsonoid/kube-crds-and-controllers/controllers/crd-configured/pkg/labeling"
+)
+
+// rawConfig returns a config without a spec, the review only has to pass the objects through in order
+func rawConfig(t *testing.T, apiVersion, kind, name string) runtime.RawExtension {
+	c := v1alpha1.PodLabelConfig{
+		TypeMeta:   metav1.TypeMeta{APIVersion: apiVersion, Kind: kind},
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+	}
+	raw, err := json.Marshal(c)
+	if err != nil {
+		t.Fatalf("encoding %s: %s", name, err)
+	}
+	return runtime.RawExtension{Raw: raw}
+}
+
+func TestServeConversion(t *testing.T) {
+	alpha := v1alpha1.SchemeGroupVersion.String()
+	beta := v1beta1.SchemeGroupVersion.String()
+
+	tests := []struct {
+		name    string
+		desired string
+		objects []runtime.RawExtension
+		// wantNames are the names of the converted objects, in order. nil expects a failed conversion
+		wantNames []string
+	}{
+		{
+			name:      "v1alpha1 to v1beta1",
+			desired:   beta,
+			objects:   []runtime.RawExtension{rawConfig(t, alpha, "PodLabelConfig", "first"), rawConfig(t, alpha, "PodLabelConfig", "second")},
+			wantNames: []string{"first", "second"},
+		},
+		{
+			name:      "mixed versions to v1alpha1",
+			desired:   alpha,
+			objects:   []runtime.RawExtension{rawConfig(t, beta, "PodLabelConfig", "first"), rawConfig(t, alpha, "PodLabelConfig", "second")},
+			wantNames: []string{"first", "second"},
+		},
+		{
+			name:    "one object fails",
+			desired: beta,
+			objects: []runtime.RawExtension{rawConfig(t, alpha, "PodLabelConfig", "first"), rawConfig(t, alpha, "ResourceLabelConfig", "second")},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, err := json.Marshal(ConversionReview{
+				TypeMeta: metav1.TypeMeta{APIVersion: "apiextensions.k8s.io/v1beta1", Kind: "ConversionReview"},
+				Request: &ConversionRequest{
+					UID:               "0d3f6c12-1f2e-11e8-b467-0ed5f89f718b",
+					DesiredAPIVersion: tt.desired,
+					Objects:           tt.objects,
+				},
+			})
+			if err != nil {
+				t.Fatalf("encoding review: %s", err)
+			}
+
+			req := httptest.NewRequest(http.MethodPost, "/convert-podlabelconfigs", bytes.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			rec := httptest.NewRecorder()
+			ServeConversion(v1beta1.ConvertPodLabelConfig)(rec, req)
+
+			if rec.Code != http.StatusOK {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, http.StatusOK, rec.Body.String())
+			}
+
+			review := ConversionReview{}
+			if err := json.Unmarshal(rec.Body.Bytes(), &review); err != nil {
+				t.Fatalf("decoding response: %s", err)
+			}
+			if review.Request != nil {
+				t.Errorf("request was sent back")
+			}
+			if review.Kind != "ConversionReview" || review.APIVersion != "apiextensions.k8s.io/v1beta1" {
+				t.Errorf("response is a %s %s, want the ConversionReview it was sent", review.APIVersion, review.Kind)
+			}
+			if review.Response == nil {
+				t.Fatalf("no response")
+			}
+			if review.Response.UID != "0d3f6c12-1f2e-11e8-b467-0ed5f89f718b" {
+				t.Errorf("UID = %q, want the UID of the request", review.Response.UID)
+			}
+
+			if tt.wantNames == nil {
+				if review.Response.Result.Status != metav1.StatusFailure || len(review.Response.ConvertedObjects) != 0 {
+					t.Errorf("expected a failure without objects, got %#v", review.Response)
+				}
+				return
+			}
+
+			if review.Response.Result.Status != metav1.StatusSuccess {
+				t.Fatalf("result = %#v, want success", review.Response.Result)
+			}
+			if len(review.Response.ConvertedObjects) != len(tt.wantNames) {
+				t.Fatalf("got %d objects, want %d", len(review.Response.ConvertedObjects), len(tt.wantNames))
+			}
+			for i, object := range review.Response.ConvertedObjects {
+				converted := struct {
+					metav1.TypeMeta   `json:",inline"`
+					metav1.ObjectMeta `json:"metadata"`
+				}{}
+				if err := json.Unmarshal(object.Raw, &converted); err != nil {
+					t.Fatalf("decoding object %d: %s", i, err)
+				}
+				if converted.APIVersion != tt.desired {
+					t.Errorf("object %d has apiVersion %q, want %q", i, converted.APIVersion, tt.desired)
+				}
+				if converted.Name != tt.wantNames[i] {
+					t.Errorf("object %d is %q, want %q", i, converted.Name, tt.wantNames[i])
+				}
+			}
+		})
+	}
+}
+
+func TestServeConversionContentType(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/convert-podlabelconfigs", bytes.NewReader([]byte("{}")))
+	req.Header.Set("Content-Type", "text/plain")
+	rec := httptest.NewRecorder()
+	ServeConversion(v1beta1.ConvertPodLabelConfig)(rec, req)
+
+	if rec.Code != http.StatusUnsupportedMediaType {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusUnsupportedMediaType)
+	}
+}
+
+func TestMetadataPatch(t *testing.T) {
+	tracking := map[string]string{labeling.ManagedLabelsAnnotation: `{"test1":["app"]}`}
+
+	tests := []struct {
+		name           string
+		labels         map[string]string
+		annotations    map[string]string
+		newLabels      map[string]string
+		newAnnotations map[string]string
+		want           []JSONPatchOp
+	}{
+		{
+			name:           "unchanged",
+			newLabels:      map[string]string{},
+			newAnnotations: map[string]string{},
+			want:           []JSONPatchOp{},
+		},
+		{
+			name:           "label with its tracking annotation",
+			newLabels:      map[string]string{"app": "web"},
+			newAnnotations: tracking,
+			want: []JSONPatchOp{
+				{Op: "add", Path: "/metadata/labels", Value: map[string]string{"app": "web"}},
+				{Op: "add", Path: "/metadata/annotations", Value: tracking},
+			},
+		},
+		{
+			name:           "only the tracking annotation",
+			labels:         map[string]string{"app": "web"},
+			newLabels:      map[string]string{"app": "web"},
+			newAnnotations: tracking,
+			want: []JSONPatchOp{
+				{Op: "add", Path: "/metadata/annotations", Value: tracking},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Labels: tt.labels, Annotations: tt.annotations}}
+			newPod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Labels: tt.newLabels, Annotations: tt.newAnnotations}}
+
+			if got := MetadataPatch(pod, newPod); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("MetadataPatch() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
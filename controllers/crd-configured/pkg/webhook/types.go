@@ -0,0 +1,142 @@
+// Package webhook holds the wire types used to talk to the apiserver as an admission or conversion webhook.
+//
+// The vendored k8s.io/api predates admission.k8s.io/v1beta1 and apiextensions.k8s.io/v1beta1 conversion,
+// so the parts of the AdmissionReview and ConversionReview that the webhooks need are declared here. The json names match the upstream types exactly.
+package webhook
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// Operation is the type of resource operation being checked for admission control
+type Operation string
+
+const (
+	Create  Operation = "CREATE"
+	Update  Operation = "UPDATE"
+	Delete  Operation = "DELETE"
+	Connect Operation = "CONNECT"
+)
+
+// PatchType is the type of patch returned in an AdmissionResponse
+type PatchType string
+
+const (
+	PatchTypeJSONPatch PatchType = "JSONPatch"
+)
+
+// AdmissionReview describes an admission review request/response
+type AdmissionReview struct {
+	metav1.TypeMeta `json:",inline"`
+
+	// Request describes the attributes for the admission request
+	// +optional
+	Request *AdmissionRequest `json:"request,omitempty"`
+
+	// Response describes the attributes for the admission response
+	// +optional
+	Response *AdmissionResponse `json:"response,omitempty"`
+}
+
+// AdmissionRequest describes the admission.Attributes for the admission request
+type AdmissionRequest struct {
+	// UID is an identifier for the individual request/response. It must be copied to the response
+	UID types.UID `json:"uid"`
+
+	// Kind is the type of object being manipulated
+	Kind metav1.GroupVersionKind `json:"kind"`
+
+	// Resource is the name of the resource being requested
+	Resource metav1.GroupVersionResource `json:"resource"`
+
+	// Name is the name of the object as presented in the request. It is empty on create when generateName is used
+	// +optional
+	Name string `json:"name,omitempty"`
+
+	// Namespace is the namespace associated with the request (if any)
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+
+	// Operation is the operation being performed
+	Operation Operation `json:"operation"`
+
+	// Object is the object from the incoming request prior to default values being applied
+	// +optional
+	Object runtime.RawExtension `json:"object,omitempty"`
+
+	// OldObject is the existing object. Only populated for UPDATE requests
+	// +optional
+	OldObject runtime.RawExtension `json:"oldObject,omitempty"`
+}
+
+// AdmissionResponse describes an admission response
+type AdmissionResponse struct {
+	// UID is an identifier for the individual request/response. Copied from the request
+	UID types.UID `json:"uid"`
+
+	// Allowed indicates whether or not the admission request was permitted
+	Allowed bool `json:"allowed"`
+
+	// Result contains extra details into why an admission request was denied
+	// +optional
+	Result *metav1.Status `json:"status,omitempty"`
+
+	// Patch is the patch body, only JSONPatch is supported
+	// +optional
+	Patch []byte `json:"patch,omitempty"`
+
+	// PatchType is the type of Patch
+	// +optional
+	PatchType *PatchType `json:"patchType,omitempty"`
+
+	// Warnings are returned to the client that made the request. Apiservers before Kubernetes 1.19 ignore them
+	// +optional
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// ConversionReview describes a conversion request/response sent by the apiserver for a
+// CustomResourceDefinition with a webhook conversion strategy
+type ConversionReview struct {
+	metav1.TypeMeta `json:",inline"`
+
+	// Request describes the attributes for the conversion request
+	// +optional
+	Request *ConversionRequest `json:"request,omitempty"`
+
+	// Response describes the attributes for the conversion response
+	// +optional
+	Response *ConversionResponse `json:"response,omitempty"`
+}
+
+// ConversionRequest describes the conversion request parameters
+type ConversionRequest struct {
+	// UID is an identifier for the individual request/response. It must be copied to the response
+	UID types.UID `json:"uid"`
+
+	// DesiredAPIVersion is the version to convert given objects to. e.g. "myapi.example.com/v1"
+	DesiredAPIVersion string `json:"desiredAPIVersion"`
+
+	// Objects is the list of custom resource objects to be converted
+	Objects []runtime.RawExtension `json:"objects"`
+}
+
+// ConversionResponse describes a conversion response
+type ConversionResponse struct {
+	// UID is an identifier for the individual request/response. Copied from the request
+	UID types.UID `json:"uid"`
+
+	// ConvertedObjects is the list of converted objects, in the same order as the request
+	ConvertedObjects []runtime.RawExtension `json:"convertedObjects"`
+
+	// Result contains the result of conversion with extra details if the conversion failed
+	Result metav1.Status `json:"result"`
+}
+
+// JSONPatchOp is a single RFC 6902 json patch operation
+type JSONPatchOp struct {
+	Op    string      `json:"op"`
+	Path  string      `json:"path"`
+	Value interface{} `json:"value,omitempty"`
+}
@@ -0,0 +1,74 @@
+package webhook
+
+import (
+	"fmt"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	"github.com/carsonoid/kube-crds-and-controllers/controllers/crd-configured/pkg/apis/podlabeler/v1alpha1"
+	"github.com/carsonoid/kube-crds-and-controllers/controllers/crd-configured/pkg/labeling"
+	"github.com/carsonoid/kube-crds-and-controllers/controllers/crd-configured/pkg/templates"
+)
+
+// reservedKeyPrefixes are owned by Kubernetes itself and may not be set by a config
+var reservedKeyPrefixes = []string{"kubernetes.io", "k8s.io"}
+
+// ValidateKey returns a description of every problem with a label or annotation key
+func ValidateKey(kind, key string) []string {
+	errs := []string{}
+	for _, msg := range validation.IsQualifiedName(key) {
+		errs = append(errs, fmt.Sprintf("%s key %q is invalid: %s", kind, key, msg))
+	}
+
+	if i := strings.Index(key, "/"); i >= 0 {
+		prefix := key[:i]
+		for _, reserved := range reservedKeyPrefixes {
+			if prefix == reserved || strings.HasSuffix(prefix, "."+reserved) {
+				errs = append(errs, fmt.Sprintf("%s key %q uses the reserved prefix %s/", kind, key, reserved))
+			}
+		}
+	}
+	return errs
+}
+
+// ValidatePodLabelConfigSpec returns a description of every problem with the keys, values, podSelector
+// and mode of a config. Conflicts with other configs depend on the cluster and are left to the caller.
+func ValidatePodLabelConfigSpec(spec *v1alpha1.PodLabelConfigSpec) []string {
+	errs := []string{}
+
+	for _, k := range labeling.SortedKeys(spec.Labels) {
+		errs = append(errs, ValidateKey("label", k)...)
+
+		v := spec.Labels[k]
+		if templates.IsTemplate(v) {
+			// Templated values are sanitized when they are rendered, only the template itself can be checked
+			if _, err := templates.Parse(nil, nil, v); err != nil {
+				errs = append(errs, fmt.Sprintf("label %s has an invalid template: %s", k, err))
+			}
+			continue
+		}
+		for _, msg := range validation.IsValidLabelValue(v) {
+			errs = append(errs, fmt.Sprintf("label %s has an invalid value %q: %s", k, v, msg))
+		}
+	}
+
+	for _, k := range labeling.SortedKeys(spec.Annotations) {
+		errs = append(errs, ValidateKey("annotation", k)...)
+	}
+
+	if spec.PodSelector != nil {
+		if _, err := metav1.LabelSelectorAsSelector(spec.PodSelector); err != nil {
+			errs = append(errs, fmt.Sprintf("invalid podSelector: %s", err))
+		}
+	}
+
+	switch spec.Mode {
+	case "", v1alpha1.PodLabelConfigModeEnforce, v1alpha1.PodLabelConfigModeIfAbsent, v1alpha1.PodLabelConfigModeAudit:
+	default:
+		errs = append(errs, fmt.Sprintf("invalid mode %q, must be Enforce, IfAbsent or Audit", spec.Mode))
+	}
+
+	return errs
+}
@@ -0,0 +1,109 @@
+package webhook
+
+import (
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/carsonoid/kube-crds-and-controllers/controllers/crd-configured/pkg/apis/podlabeler/v1alpha1"
+)
+
+func TestValidateKey(t *testing.T) {
+	tests := []struct {
+		key     string
+		wantErr bool
+	}{
+		{key: "team"},
+		{key: "example.com/team"},
+		{key: "podlabeler.k8s.carsonoid.net/team"},
+		{key: "", wantErr: true},
+		{key: "-team", wantErr: true},
+		{key: "team name", wantErr: true},
+		{key: "Example_Com/team", wantErr: true},
+		{key: strings.Repeat("a", 64), wantErr: true},
+		{key: "kubernetes.io/team", wantErr: true},
+		{key: "node.kubernetes.io/team", wantErr: true},
+		{key: "k8s.io/team", wantErr: true},
+		{key: "apps.k8s.io/team", wantErr: true},
+		{key: "notkubernetes.io/team"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			errs := ValidateKey("label", tt.key)
+			if (len(errs) > 0) != tt.wantErr {
+				t.Errorf("ValidateKey(%q) = %v, want error %t", tt.key, errs, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidatePodLabelConfigSpec(t *testing.T) {
+	tests := []struct {
+		name string
+		spec v1alpha1.PodLabelConfigSpec
+		// want are substrings of the expected errors, in order
+		want []string
+	}{
+		{
+			name: "empty",
+			spec: v1alpha1.PodLabelConfigSpec{},
+		},
+		{
+			name: "valid",
+			spec: v1alpha1.PodLabelConfigSpec{
+				Labels:      map[string]string{"team": "web", "node": "{{ .Spec.NodeName }}"},
+				Annotations: map[string]string{"example.com/contact": "web team <web@example.com>"},
+				PodSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+				Mode:        v1alpha1.PodLabelConfigModeIfAbsent,
+			},
+		},
+		{
+			name: "invalid label value",
+			spec: v1alpha1.PodLabelConfigSpec{Labels: map[string]string{"team": "web team"}},
+			want: []string{`label team has an invalid value "web team"`},
+		},
+		{
+			name: "invalid template",
+			spec: v1alpha1.PodLabelConfigSpec{Labels: map[string]string{"owner": "{{ owner }}"}},
+			want: []string{"label owner has an invalid template"},
+		},
+		{
+			name: "reserved annotation key",
+			spec: v1alpha1.PodLabelConfigSpec{Annotations: map[string]string{"kubernetes.io/team": "web"}},
+			want: []string{`annotation key "kubernetes.io/team" uses the reserved prefix kubernetes.io/`},
+		},
+		{
+			name: "invalid selector",
+			spec: v1alpha1.PodLabelConfigSpec{PodSelector: &metav1.LabelSelector{
+				MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "app", Operator: "Near"}},
+			}},
+			want: []string{"invalid podSelector"},
+		},
+		{
+			name: "invalid mode",
+			spec: v1alpha1.PodLabelConfigSpec{Mode: "Sometimes"},
+			want: []string{`invalid mode "Sometimes"`},
+		},
+		{
+			name: "errors in key order",
+			spec: v1alpha1.PodLabelConfigSpec{Labels: map[string]string{"b": "web team", "a/b/c": "web"}},
+			want: []string{`label key "a/b/c" is invalid`, "label b has an invalid value"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := ValidatePodLabelConfigSpec(&tt.spec)
+			if len(errs) != len(tt.want) {
+				t.Fatalf("got errors %v, want %d errors", errs, len(tt.want))
+			}
+			for i := range errs {
+				if !strings.Contains(errs[i], tt.want[i]) {
+					t.Errorf("error %d = %q, want it to contain %q", i, errs[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
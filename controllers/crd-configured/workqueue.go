@@ -8,108 +8,413 @@ import (
 	"flag"
 	"fmt"
 	logging "log"
+	"net/http"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
 	"time"
 
+	// Prometheus
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
 	// Kubernetes and client-go
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
 	machinery_runtime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/runtime"
 	"k8s.io/apimachinery/pkg/util/strategicpatch"
+	"k8s.io/apimachinery/pkg/util/uuid"
 	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/informers"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	typedcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	appslisters "k8s.io/client-go/listers/apps/v1beta2"
+	batchlisters "k8s.io/client-go/listers/batch/v1"
+	batchv1beta1listers "k8s.io/client-go/listers/batch/v1beta1"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	extensionslisters "k8s.io/client-go/listers/extensions/v1beta1"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/flowcontrol"
 	"k8s.io/client-go/util/workqueue"
 
 	// Custom resources
 	plv1alpha1 "github.com/carsonoid/kube-crds-and-controllers/controllers/crd-configured/pkg/apis/podlabeler/v1alpha1"
+	plv1beta1 "github.com/carsonoid/kube-crds-and-controllers/controllers/crd-configured/pkg/apis/podlabeler/v1beta1"
 	plclient "github.com/carsonoid/kube-crds-and-controllers/controllers/crd-configured/pkg/client/clientset/versioned"
+	plscheme "github.com/carsonoid/kube-crds-and-controllers/controllers/crd-configured/pkg/client/clientset/versioned/scheme"
+	plinformers "github.com/carsonoid/kube-crds-and-controllers/controllers/crd-configured/pkg/client/informers/externalversions"
+	pllisters "github.com/carsonoid/kube-crds-and-controllers/controllers/crd-configured/pkg/client/listers/podlabeler/v1alpha1"
+	"github.com/carsonoid/kube-crds-and-controllers/controllers/crd-configured/pkg/clusterconfigs"
+	"github.com/carsonoid/kube-crds-and-controllers/controllers/crd-configured/pkg/exclusions"
+	"github.com/carsonoid/kube-crds-and-controllers/controllers/crd-configured/pkg/finalizers"
+	"github.com/carsonoid/kube-crds-and-controllers/controllers/crd-configured/pkg/labeling"
+	"github.com/carsonoid/kube-crds-and-controllers/controllers/crd-configured/pkg/podconfigs"
+	"github.com/carsonoid/kube-crds-and-controllers/controllers/crd-configured/pkg/resourceconfigs"
+	"github.com/carsonoid/kube-crds-and-controllers/controllers/crd-configured/pkg/resources"
+	"github.com/carsonoid/kube-crds-and-controllers/controllers/crd-configured/pkg/selectors"
+	"github.com/carsonoid/kube-crds-and-controllers/controllers/crd-configured/pkg/templates"
+	"github.com/carsonoid/kube-crds-and-controllers/controllers/crd-configured/pkg/webhook"
+	"github.com/carsonoid/kube-crds-and-controllers/pkg/election"
+	_ "github.com/carsonoid/kube-crds-and-controllers/pkg/metrics" // workqueue metrics
 )
 
+// BONUS: These values could be read from flags
+const PodLabelConfigFinalizer string = "podlabelconfig.finalizers.k8s.carsonoid.net"
+const ClusterPodLabelConfigFinalizer string = "clusterpodlabelconfig.finalizers.k8s.carsonoid.net"
+const ResourceLabelConfigFinalizer string = "resourcelabelconfig.finalizers.k8s.carsonoid.net"
+
+// ApplyPatchType is the content type of server-side apply requests, the vendored apimachinery predates it
+const ApplyPatchType types.PatchType = "application/apply-patch+yaml"
+
 var (
 	log = logging.New(os.Stdout, "", logging.Lshortfile)
+
+	podPatches = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: "podlabeler",
+		Name:      "pod_patches_total",
+		Help:      "Total number of pods patched",
+	})
+	podPatchFailures = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: "podlabeler",
+		Name:      "pod_patch_failures_total",
+		Help:      "Total number of pod patches that failed",
+	})
+	auditedPatches = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: "podlabeler",
+		Name:      "audited_patches_total",
+		Help:      "Total number of pod patches that were only logged because of audit mode",
+	})
+	droppedKeys = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: "podlabeler",
+		Name:      "dropped_keys_total",
+		Help:      "Total number of pod, workload and resource keys dropped from the queues after too many retries",
+	})
+	applyConflicts = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: "podlabeler",
+		Name:      "apply_conflicts_total",
+		Help:      "Total number of server-side applies rejected because another field manager owns a key",
+	})
 )
 
+func init() {
+	prometheus.MustRegister(podPatches, podPatchFailures, auditedPatches, droppedKeys, applyConflicts)
+}
+
 // PodLabelController with a config and client
 type PodLabelController struct {
 	client      *kubernetes.Clientset
 	plClientset *plclient.Clientset
 	HasSynced   bool
+	recorder    record.EventRecorder
+
+	// dryRun puts every config in audit mode, pods are never patched
+	dryRun *bool
+
+	// excluded holds the namespaces, owner kinds and pod phases that are never labelled
+	excluded exclusions.Rules
+
+	// apply switches pods from strategic merge patches to server-side apply, nil keeps the patches
+	apply *serverSideApply
+	// applyConflictBackoff delays the retries of pods whose apply conflicted with another field manager
+	applyConflictBackoff workqueue.RateLimiter
+
+	// writeLimiter throttles the patches sent for pods, workloads and resources, on top of the client QPS
+	writeLimiter flowcontrol.RateLimiter
+
+	// coalesceWindow delays queued pods, so every change arriving within it is patched at once
+	coalesceWindow time.Duration
+
+	// All reads go through the shared informers of these factories, so every resource is watched once
+	kubeInformerFactory informers.SharedInformerFactory
+	plInformerFactory   plinformers.SharedInformerFactory
+
+	podLabelConfigLister pllisters.PodLabelConfigLister
+	// Status, conflicts and finalizers of PodLabelConfigs are written by a worker once their pods are processed
+	configs *podconfigs.Controller
+	// statusDelay spaces the checks of a queued config for pods that are still queued or being processed
+	statusDelay workqueue.RateLimiter
+
+	clusterPodLabelConfigLister pllisters.ClusterPodLabelConfigLister
+	// clusterConfigs queues the pods of ClusterPodLabelConfigs, their finalizers are written by its worker
+	clusterConfigs *clusterconfigs.Controller
+
+	namespaceLister   corelisters.NamespaceLister
+	namespaceInformer cache.SharedIndexInformer
+
+	// Pod owners are looked up to render the ownerKind and ownerName templates
+	replicaSetLister extensionslisters.ReplicaSetLister
+	jobLister        batchlisters.JobLister
 
-	podLabelConfigStore      cache.Store
-	podLabelConfigController cache.Controller
+	// Workloads get their pod templates labelled by configs with podTemplates set
+	deploymentLister  appslisters.DeploymentLister
+	statefulSetLister appslisters.StatefulSetLister
+	daemonSetLister   appslisters.DaemonSetLister
+	cronJobLister     batchv1beta1listers.CronJobLister
+	workloadQueue     workqueue.RateLimitingInterface
+
+	// ResourceLabelConfigs name any resource, which is watched through the dynamic client
+	resourceLabelConfigLister pllisters.ResourceLabelConfigLister
+	resourceWatches           *resources.Watches
+	resourceQueue             workqueue.RateLimitingInterface
+	// resourceConfigs starts the watches of ResourceLabelConfigs and writes their finalizers, discovery is only
+	// called by its worker
+	resourceConfigs *resourceconfigs.Controller
 
 	numPodWorkers *int
 	podIndexer    cache.Indexer
 	podQueue      workqueue.RateLimitingInterface
-	podInformer   cache.Controller
+	podInformer   cache.SharedIndexInformer
+
+	// workers tracks the running queue workers so shutdown can wait for in-flight items
+	workers sync.WaitGroup
+
+	// Health tracking, all values are accessed atomically
+	// podsSynced is 1 once the pod informer has synced and the workers are started
+	podsSynced int32
+	// lastPodProcessed is the unix time the last pod was processed, used to detect wedged workers
+	lastPodProcessed int64
+	// lastNamespaceEvent is the unix time of the last namespace event or resync, used to detect a broken watch
+	lastNamespaceEvent int64
+	// podsProcessing is the number of pods the workers are handling right now
+	podsProcessing int32
 }
 
 // NewPodLabelController takes a kubernetes clientset and configuration and returns a valid PodLabelController
-func NewPodLabelController(client *kubernetes.Clientset, plClientset *plclient.Clientset, numPodWorkers *int) *PodLabelController {
-	return &PodLabelController{
-		client:        client,
-		plClientset:   plClientset,
-		numPodWorkers: numPodWorkers,
+func NewPodLabelController(client *kubernetes.Clientset, plClientset *plclient.Clientset, clientPool dynamic.ClientPool, numPodWorkers *int, dryRun *bool, excluded exclusions.Rules, apply *serverSideApply, writeLimiter flowcontrol.RateLimiter, coalesceWindow time.Duration) *PodLabelController {
+	// Events are recorded on pods and on the configs which changed them.
+	// The custom types must be known to the scheme to reference them from events
+	plscheme.AddToScheme(scheme.Scheme)
+	eventBroadcaster := record.NewBroadcaster()
+	eventBroadcaster.StartRecordingToSink(&typedcorev1.EventSinkImpl{Interface: client.CoreV1().Events("")})
+
+	// Namespaces resync every minute, configs every 30 seconds so failed finalizations are retried
+	kubeInformerFactory := informers.NewSharedInformerFactory(client, time.Minute)
+	plInformerFactory := plinformers.NewSharedInformerFactory(plClientset, time.Second*30)
+
+	podInformer := kubeInformerFactory.Core().V1().Pods().Informer()
+	plc := &PodLabelController{
+		client:                      client,
+		plClientset:                 plClientset,
+		recorder:                    eventBroadcaster.NewRecorder(scheme.Scheme, corev1.EventSource{Component: "podlabeler"}),
+		dryRun:                      dryRun,
+		excluded:                    excluded,
+		apply:                       apply,
+		applyConflictBackoff:        workqueue.NewItemExponentialFailureRateLimiter(5*time.Second, 5*time.Minute),
+		writeLimiter:                writeLimiter,
+		coalesceWindow:              coalesceWindow,
+		kubeInformerFactory:         kubeInformerFactory,
+		plInformerFactory:           plInformerFactory,
+		podLabelConfigLister:        plInformerFactory.Podlabeler().V1alpha1().PodLabelConfigs().Lister(),
+		statusDelay:                 workqueue.NewItemExponentialFailureRateLimiter(500*time.Millisecond, 10*time.Second),
+		clusterPodLabelConfigLister: plInformerFactory.Podlabeler().V1alpha1().ClusterPodLabelConfigs().Lister(),
+		namespaceLister:             kubeInformerFactory.Core().V1().Namespaces().Lister(),
+		namespaceInformer:           kubeInformerFactory.Core().V1().Namespaces().Informer(),
+		replicaSetLister:            kubeInformerFactory.Extensions().V1beta1().ReplicaSets().Lister(),
+		jobLister:                   kubeInformerFactory.Batch().V1().Jobs().Lister(),
+		deploymentLister:            kubeInformerFactory.Apps().V1beta2().Deployments().Lister(),
+		statefulSetLister:           kubeInformerFactory.Apps().V1beta2().StatefulSets().Lister(),
+		daemonSetLister:             kubeInformerFactory.Apps().V1beta2().DaemonSets().Lister(),
+		cronJobLister:               kubeInformerFactory.Batch().V1beta1().CronJobs().Lister(),
+		resourceLabelConfigLister:   plInformerFactory.Podlabeler().V1alpha1().ResourceLabelConfigs().Lister(),
+		numPodWorkers:               numPodWorkers,
+		// The shared pod informer is indexed by namespace
+		podIndexer:  podInformer.GetIndexer(),
+		podInformer: podInformer,
+	}
+
+	// The config kinds share how their finalizers are added and removed
+	configs := plClientset.PodlabelerV1alpha1()
+	plc.configs = &podconfigs.Controller{
+		Lister:          plc.podLabelConfigLister,
+		Informer:        plInformerFactory.Podlabeler().V1alpha1().PodLabelConfigs().Informer(),
+		Client:          plClientset,
+		ClusterLister:   plc.clusterPodLabelConfigLister,
+		NamespaceLister: plc.namespaceLister,
+		Configs: finalizers.Kind{
+			Resource:  plv1alpha1.Resource("podlabelconfigs"),
+			Finalizer: PodLabelConfigFinalizer,
+			Indexer:   plInformerFactory.Podlabeler().V1alpha1().PodLabelConfigs().Informer().GetIndexer(),
+			Get: func(namespace, name string) (metav1.Object, error) {
+				return configs.PodLabelConfigs(namespace).Get(name, metav1.GetOptions{})
+			},
+			Update: func(obj metav1.Object) error {
+				_, err := configs.PodLabelConfigs(obj.GetNamespace()).Update(obj.(*plv1alpha1.PodLabelConfig))
+				return err
+			},
+		},
+		HasSynced:      plc.hasSynced,
+		CoalesceWindow: coalesceWindow,
+		EnqueuePods:    plc.enqueueSelected,
+		EnqueueOwned:   plc.enqueueOwned,
+		Pods:           plc.namespacePods,
+		IsExcluded:     plc.isExcluded,
+	}
+	plc.clusterConfigs = &clusterconfigs.Controller{
+		Lister:          plc.clusterPodLabelConfigLister,
+		Informer:        plInformerFactory.Podlabeler().V1alpha1().ClusterPodLabelConfigs().Informer(),
+		NamespaceLister: plc.namespaceLister,
+		Configs: finalizers.Kind{
+			Resource:  plv1alpha1.Resource("clusterpodlabelconfigs"),
+			Finalizer: ClusterPodLabelConfigFinalizer,
+			Indexer:   plInformerFactory.Podlabeler().V1alpha1().ClusterPodLabelConfigs().Informer().GetIndexer(),
+			Get: func(namespace, name string) (metav1.Object, error) {
+				return configs.ClusterPodLabelConfigs().Get(name, metav1.GetOptions{})
+			},
+			Update: func(obj metav1.Object) error {
+				_, err := configs.ClusterPodLabelConfigs().Update(obj.(*plv1alpha1.ClusterPodLabelConfig))
+				return err
+			},
+		},
+		HasSynced:        plc.hasSynced,
+		EnqueueNamespace: plc.enqueueNamespace,
+		EnqueueOwned:     plc.enqueueOwned,
+	}
+	plc.resourceWatches = resources.NewWatches(client.Discovery(), clientPool, func(key resources.Key) {
+		plc.resourceQueue.Add(key)
+	})
+	plc.resourceConfigs = &resourceconfigs.Controller{
+		Lister:   plc.resourceLabelConfigLister,
+		Informer: plInformerFactory.Podlabeler().V1alpha1().ResourceLabelConfigs().Informer(),
+		Recorder: plc.recorder,
+		Configs: finalizers.Kind{
+			Resource:  plv1alpha1.Resource("resourcelabelconfigs"),
+			Finalizer: ResourceLabelConfigFinalizer,
+			Indexer:   plInformerFactory.Podlabeler().V1alpha1().ResourceLabelConfigs().Informer().GetIndexer(),
+			Get: func(namespace, name string) (metav1.Object, error) {
+				return configs.ResourceLabelConfigs().Get(name, metav1.GetOptions{})
+			},
+			Update: func(obj metav1.Object) error {
+				_, err := configs.ResourceLabelConfigs().Update(obj.(*plv1alpha1.ResourceLabelConfig))
+				return err
+			},
+		},
+		Watches:   plc.resourceWatches,
+		HasSynced: plc.hasSynced,
 	}
+	return plc
+}
+
+// RunWithLeaderElection only runs the PodLabelController while this replica holds the lock.
+// Standby replicas block until they are elected. Losing the lock exits the process so no work is done twice.
+// Standbys return as soon as stopCh is closed, the leader returns once Run has drained its queue and the lock
+// is released.
+func (plc *PodLabelController) RunWithLeaderElection(stopCh <-chan struct{}, drainTimeout time.Duration, lock resourcelock.Interface, leaseDuration, renewDeadline, retryPeriod time.Duration) {
+	election.Run(stopCh, lock, leaseDuration, renewDeadline, retryPeriod, func(stopCh <-chan struct{}) {
+		plc.Run(stopCh, drainTimeout)
+	})
 }
 
-// Run starts the PodLabelController and blocks until killed
-func (plc *PodLabelController) Run() {
+// hasSynced reports if the initial sync of the informers is complete
+func (plc *PodLabelController) hasSynced() bool {
+	return plc.HasSynced
+}
+
+// Run starts the PodLabelController and blocks until stopCh is closed.
+// On shutdown the pod queue is drained for up to drainTimeout so no patch is interrupted.
+func (plc *PodLabelController) Run(stopCh <-chan struct{}, drainTimeout time.Duration) {
 	killChan := make(chan struct{})
-	defer close(killChan)
+	go func() {
+		<-stopCh
+		close(killChan)
+	}()
 
-	// Start watching PodLabelConfigs
-	plc.StartPodLabelConfigController(killChan)
+	// Watch Namespaces, PodLabelConfigs, ClusterPodLabelConfigs, ResourceLabelConfigs and Pods
+	plc.StartNamespaceController(killChan)
+	plc.configs.Start()
+	plc.clusterConfigs.Start()
+	// Objects of the watched resources are labelled by the resource workers
+	plc.resourceQueue = workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "resourceQueue")
+	plc.resourceConfigs.Start(killChan)
+	plc.StartPodController(killChan)
+	plc.StartWorkloadController(killChan)
+
+	// Every informer requested from the factories is started once all handlers are registered
+	plc.kubeInformerFactory.Start(killChan)
+	plc.plInformerFactory.Start(killChan)
 
 	log.Print("Waiting for initial PodLabelConfig sync")
 
-	// Wait for store to sync up before processing pods
-	if !cache.WaitForCacheSync(killChan, plc.podLabelConfigController.HasSynced) {
-		runtime.HandleError(fmt.Errorf("Timed out waiting for caches to sync"))
-		return
+	// Wait for stores to sync up before processing pods. Config changes queue pods from the pod
+	// cache, so it has to be synced as well
+	synced := plc.kubeInformerFactory.WaitForCacheSync(killChan)
+	for informerType, ok := range plc.plInformerFactory.WaitForCacheSync(killChan) {
+		synced[informerType] = ok
+	}
+	for informerType, ok := range synced {
+		if !ok {
+			runtime.HandleError(fmt.Errorf("Timed out waiting for %v caches to sync", informerType))
+			return
+		}
 	}
 
 	plc.HasSynced = true
 
 	log.Print("Initial PodLabelConfig sync complete")
 
-	// Start pod controller
-	go plc.StartPodController(killChan)
+	// Start processing the pods queued so far. Every config was queued by the initial sync, so their
+	// status and conflicts are written once the workers are through these pods
+	go plc.StartQueueWorkers(*plc.numPodWorkers, killChan)
 	<-killChan
+
+	plc.drainWorkers(drainTimeout)
+}
+
+// drainWorkers waits for the queue workers to finish the items they are processing, for up to timeout
+func (plc *PodLabelController) drainWorkers(timeout time.Duration) {
+	log.Printf("Waiting up to %s for in-flight pods to finish", timeout)
+
+	drained := make(chan struct{})
+	go func() {
+		plc.workers.Wait()
+		close(drained)
+	}()
+
+	select {
+	case <-drained:
+		log.Print("All pod workers stopped")
+	case <-time.After(timeout):
+		log.Printf("Timed out waiting for pod workers, %d pods, %d workloads, %d resources and %d configs left in the queues", plc.podQueue.Len(), plc.workloadQueue.Len(), plc.resourceQueue.Len(), plc.configs.Queue.Len())
+	}
 }
 
+// StartPodController creates the pod queue and registers the pod event handlers on the shared informer.
+// The informer itself is started by Run.
 func (plc *PodLabelController) StartPodController(killChan chan struct{}) {
 	log.Println("Starting Pod controller")
 
-	restClient := plc.client.CoreV1().RESTClient()
-	listwatch := cache.NewListWatchFromClient(restClient, "pods", corev1.NamespaceAll, fields.Everything())
-
-	plc.podQueue = workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter())
+	// The queue is named so its metrics can be told apart
+	plc.podQueue = workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "podQueue")
 
-	plc.podIndexer, plc.podInformer = cache.NewIndexerInformer(listwatch, &corev1.Pod{}, 0,
+	// Pods are never resynced, every change already queues them
+	plc.podInformer.AddEventHandlerWithResyncPeriod(
 		cache.ResourceEventHandlerFuncs{
 			AddFunc: func(obj interface{}) {
 				log.Print("Pod Add Event")
-				key, err := cache.MetaNamespaceKeyFunc(obj)
-				if err == nil {
-					plc.podQueue.Add(key)
-				}
+				plc.enqueuePod(obj)
 			},
 			UpdateFunc: func(oldobj interface{}, newobj interface{}) {
 				log.Print("Pod Update Event")
 				// Make sure object is not set for deltion and was actually changed
 				if newobj.(*corev1.Pod).GetDeletionTimestamp() == nil &&
 					oldobj.(*corev1.Pod).GetResourceVersion() != newobj.(*corev1.Pod).GetResourceVersion() {
-					key, err := cache.MetaNamespaceKeyFunc(newobj)
-					if err == nil {
-						plc.podQueue.Add(key)
-					}
+					plc.enqueuePod(newobj)
 				}
 			},
 			DeleteFunc: func(obj interface{}) {
@@ -120,12 +425,363 @@ func (plc *PodLabelController) StartPodController(killChan chan struct{}) {
 					plc.podQueue.Add(key)
 				}
 			},
-		}, cache.Indexers{})
+		}, 0)
+
+	// Config changes look up the pods of a namespace through the namespace index
+	// and queue their keys, so they are patched by the same workers with the same retries
+}
 
-	// Watch for config reloads and then restart the controller so all existing pods
-	// are re-evaluted with new config
+// enqueuePod adds the key of a pod to the pod queue after the coalesce window. Excluded pods are skipped unless
+// they still have keys to remove.
+// A key that is already waiting is not added again, so all changes within the window end up in one patch
+func (plc *PodLabelController) enqueuePod(obj interface{}) {
+	if pod, ok := obj.(*corev1.Pod); ok && !plc.needsStripping(pod) && plc.isExcluded(pod) {
+		return
+	}
+	key, err := cache.MetaNamespaceKeyFunc(obj)
+	if err == nil {
+		plc.podQueue.AddAfter(key, plc.coalesceWindow)
+	}
+}
 
-	go plc.StartQueueWorkers(*plc.numPodWorkers, killChan)
+// namespacePods returns the cached pods in a namespace, or in all namespaces for corev1.NamespaceAll
+func (plc *PodLabelController) namespacePods(namespace string) []*corev1.Pod {
+	var objs []interface{}
+	if namespace == corev1.NamespaceAll {
+		objs = plc.podIndexer.List()
+	} else {
+		var err error
+		if objs, err = plc.podIndexer.ByIndex(cache.NamespaceIndex, namespace); err != nil {
+			log.Printf("Error listing pods in namespace %s: %s", namespace, err)
+			return nil
+		}
+	}
+
+	pods := make([]*corev1.Pod, 0, len(objs))
+	for _, obj := range objs {
+		pods = append(pods, obj.(*corev1.Pod))
+	}
+	return pods
+}
+
+// enqueuePods adds every cached pod in the namespace matched by the selector to the pod queue
+func (plc *PodLabelController) enqueuePods(namespace string, selector labels.Selector) {
+	for _, pod := range plc.namespacePods(namespace) {
+		if selector.Matches(labels.Set(pod.GetLabels())) {
+			plc.enqueuePod(pod)
+		}
+	}
+}
+
+// enqueueOwnedPods adds every cached pod in the namespace that still has keys set by owner to the pod queue
+// and returns how many were found. Excluded pods have all their keys removed and are counted too, except for
+// pods in an excluded phase which keep their keys
+func (plc *PodLabelController) enqueueOwnedPods(namespace, owner string) int {
+	owned := 0
+	for _, pod := range plc.namespacePods(namespace) {
+		if labeling.HasKeysFrom(pod, owner) && (plc.needsStripping(pod) || !plc.isExcluded(pod)) {
+			plc.enqueuePod(pod)
+			owned++
+		}
+	}
+	return owned
+}
+
+// StartWorkloadController creates the workload queue and registers the event handlers for the workloads
+// with pod templates on the shared informers. The informers themselves are started by Run.
+func (plc *PodLabelController) StartWorkloadController(killChan chan struct{}) {
+	log.Println("Starting Workload controller")
+
+	// The queue is named so its metrics can be told apart
+	plc.workloadQueue = workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "workloadQueue")
+
+	for _, w := range []struct {
+		kind     string
+		informer cache.SharedIndexInformer
+	}{
+		{"Deployment", plc.kubeInformerFactory.Apps().V1beta2().Deployments().Informer()},
+		{"StatefulSet", plc.kubeInformerFactory.Apps().V1beta2().StatefulSets().Informer()},
+		{"DaemonSet", plc.kubeInformerFactory.Apps().V1beta2().DaemonSets().Informer()},
+		{"CronJob", plc.kubeInformerFactory.Batch().V1beta1().CronJobs().Informer()},
+	} {
+		kind := w.kind
+		w.informer.AddEventHandlerWithResyncPeriod(
+			cache.ResourceEventHandlerFuncs{
+				AddFunc: func(obj interface{}) {
+					plc.enqueueWorkload(kind, obj)
+				},
+				UpdateFunc: func(oldobj interface{}, newobj interface{}) {
+					if oldobj.(metav1.Object).GetResourceVersion() != newobj.(metav1.Object).GetResourceVersion() {
+						plc.enqueueWorkload(kind, newobj)
+					}
+				},
+			}, 0)
+	}
+}
+
+// enqueueWorkload adds the key of a workload to the workload queue. Keys are prefixed with the kind
+func (plc *PodLabelController) enqueueWorkload(kind string, obj interface{}) {
+	key, err := cache.MetaNamespaceKeyFunc(obj)
+	if err == nil {
+		plc.workloadQueue.Add(kind + "/" + key)
+	}
+}
+
+// enqueueWorkloads adds every cached workload in the namespace with a pod template matched by the selector
+// to the workload queue
+func (plc *PodLabelController) enqueueWorkloads(namespace string, selector labels.Selector) {
+	for _, workload := range plc.namespaceWorkloads(namespace) {
+		if selector.Matches(labels.Set(workload.GetLabels())) {
+			plc.workloadQueue.Add(workload.GetName())
+		}
+	}
+}
+
+// enqueueOwnedWorkloads adds every cached workload in the namespace with a pod template that still has keys set
+// by owner to the workload queue and returns how many were found
+func (plc *PodLabelController) enqueueOwnedWorkloads(namespace, owner string) int {
+	owned := 0
+	for _, workload := range plc.namespaceWorkloads(namespace) {
+		if labeling.HasKeysFrom(workload, owner) {
+			plc.workloadQueue.Add(workload.GetName())
+			owned++
+		}
+	}
+	return owned
+}
+
+// enqueueSelected adds the pods and workloads in the namespace matched by the selector to their queues
+func (plc *PodLabelController) enqueueSelected(namespace string, selector labels.Selector) {
+	plc.enqueuePods(namespace, selector)
+	plc.enqueueWorkloads(namespace, selector)
+}
+
+// enqueueNamespace adds the pods and workloads in the namespace matched by the selector to their queues, and the
+// PodLabelConfigs of the namespace to the config queue so their conflicts are checked again
+func (plc *PodLabelController) enqueueNamespace(namespace string, selector labels.Selector) {
+	plc.enqueueSelected(namespace, selector)
+	plc.configs.EnqueueNamespace(namespace)
+}
+
+// enqueueOwned adds the pods and workloads that still have keys set by owner to their queues and returns how
+// many were found
+func (plc *PodLabelController) enqueueOwned(namespace, owner string) int {
+	return plc.enqueueOwnedPods(namespace, owner) + plc.enqueueOwnedWorkloads(namespace, owner)
+}
+
+// namespaceWorkloads returns the pod templates of the cached workloads in a namespace, or in all namespaces
+// for corev1.NamespaceAll. Every template is wrapped in a pod named after the workload queue key.
+func (plc *PodLabelController) namespaceWorkloads(namespace string) []*corev1.Pod {
+	pods := []*corev1.Pod{}
+	add := func(kind string, obj metav1.Object, template *corev1.PodTemplateSpec) {
+		pods = append(pods, labeling.TemplatePod(kind+"/"+obj.GetNamespace()+"/"+obj.GetName(), obj.GetNamespace(), template))
+	}
+
+	deployments, err := plc.deploymentLister.Deployments(namespace).List(labels.Everything())
+	if err != nil {
+		log.Printf("Error listing deployments in namespace %s: %s", namespace, err)
+	}
+	for _, d := range deployments {
+		add("Deployment", d, &d.Spec.Template)
+	}
+
+	statefulSets, err := plc.statefulSetLister.StatefulSets(namespace).List(labels.Everything())
+	if err != nil {
+		log.Printf("Error listing statefulsets in namespace %s: %s", namespace, err)
+	}
+	for _, ss := range statefulSets {
+		add("StatefulSet", ss, &ss.Spec.Template)
+	}
+
+	daemonSets, err := plc.daemonSetLister.DaemonSets(namespace).List(labels.Everything())
+	if err != nil {
+		log.Printf("Error listing daemonsets in namespace %s: %s", namespace, err)
+	}
+	for _, ds := range daemonSets {
+		add("DaemonSet", ds, &ds.Spec.Template)
+	}
+
+	cronJobs, err := plc.cronJobLister.CronJobs(namespace).List(labels.Everything())
+	if err != nil {
+		log.Printf("Error listing cronjobs in namespace %s: %s", namespace, err)
+	}
+	for _, cj := range cronJobs {
+		add("CronJob", cj, &cj.Spec.JobTemplate.Spec.Template)
+	}
+	return pods
+}
+
+func (plc *PodLabelController) runWorkloadQueueWorker() {
+	for plc.processNextWorkload() {
+	}
+}
+
+func (plc *PodLabelController) processNextWorkload() bool {
+	key, quit := plc.workloadQueue.Get()
+	if quit {
+		return false
+	}
+	// Stop taking new items on shutdown, they are listed again by the informers on the next start
+	if plc.workloadQueue.ShuttingDown() {
+		plc.workloadQueue.Done(key)
+		return false
+	}
+	defer plc.workloadQueue.Done(key)
+
+	err := plc.handleWorkload(key.(string))
+	plc.handleErr(plc.workloadQueue, err, key)
+	return true
+}
+
+// getWorkload returns a copy of a cached workload along with its pod template
+func (plc *PodLabelController) getWorkload(kind, namespace, name string) (machinery_runtime.Object, *corev1.PodTemplateSpec, error) {
+	switch kind {
+	case "Deployment":
+		cached, err := plc.deploymentLister.Deployments(namespace).Get(name)
+		if err != nil {
+			return nil, nil, err
+		}
+		d := cached.DeepCopy()
+		return d, &d.Spec.Template, nil
+	case "StatefulSet":
+		cached, err := plc.statefulSetLister.StatefulSets(namespace).Get(name)
+		if err != nil {
+			return nil, nil, err
+		}
+		ss := cached.DeepCopy()
+		return ss, &ss.Spec.Template, nil
+	case "DaemonSet":
+		cached, err := plc.daemonSetLister.DaemonSets(namespace).Get(name)
+		if err != nil {
+			return nil, nil, err
+		}
+		ds := cached.DeepCopy()
+		return ds, &ds.Spec.Template, nil
+	case "CronJob":
+		cached, err := plc.cronJobLister.CronJobs(namespace).Get(name)
+		if err != nil {
+			return nil, nil, err
+		}
+		cj := cached.DeepCopy()
+		return cj, &cj.Spec.JobTemplate.Spec.Template, nil
+	}
+	return nil, nil, fmt.Errorf("unknown workload kind %s", kind)
+}
+
+// patchWorkload sends a strategic merge patch for a workload to the apiserver
+func (plc *PodLabelController) patchWorkload(kind, namespace, name string, patch []byte) error {
+	plc.writeLimiter.Accept()
+
+	var err error
+	switch kind {
+	case "Deployment":
+		_, err = plc.client.AppsV1beta2().Deployments(namespace).Patch(name, types.StrategicMergePatchType, patch)
+	case "StatefulSet":
+		_, err = plc.client.AppsV1beta2().StatefulSets(namespace).Patch(name, types.StrategicMergePatchType, patch)
+	case "DaemonSet":
+		_, err = plc.client.AppsV1beta2().DaemonSets(namespace).Patch(name, types.StrategicMergePatchType, patch)
+	case "CronJob":
+		_, err = plc.client.BatchV1beta1().CronJobs(namespace).Patch(name, types.StrategicMergePatchType, patch)
+	default:
+		err = fmt.Errorf("unknown workload kind %s", kind)
+	}
+	return err
+}
+
+// handleWorkload applies the matching configs with podTemplates set to the pod template of a workload,
+// so new pods are created with their labels. handlePod stays in place as a backstop for existing pods.
+// Jobs are not handled because their pod template is immutable, Jobs created by a CronJob get the template
+// of the CronJob instead.
+func (plc *PodLabelController) handleWorkload(key string) error {
+	parts := strings.SplitN(key, "/", 3)
+	if len(parts) != 3 {
+		log.Printf("Ignoring invalid workload key %q", key)
+		return nil
+	}
+	kind, namespace, name := parts[0], parts[1], parts[2]
+
+	workload, template, err := plc.getWorkload(kind, namespace, name)
+	if errors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if workload.(metav1.Object).GetDeletionTimestamp() != nil {
+		return nil
+	}
+
+	pod := labeling.TemplatePod(key, namespace, template)
+	newPod := labeling.TemplatePod(key, namespace, template)
+
+	// Excluded templates get the keys configs set before removed, like excluded pods
+	configs := []labeling.Source{}
+	if plc.isExcludedWorkload(kind, pod) {
+		if !plc.needsStripping(pod) {
+			return nil
+		}
+	} else {
+		configs = labeling.TemplateSources(plc.matchingConfigs(pod))
+	}
+	labelsChanged := plc.labelPod(newPod, configs)
+	annotationsChanged := plc.annotatePod(newPod, configs)
+	if !labelsChanged && !annotationsChanged {
+		return nil
+	}
+
+	log.Printf("Patching pod template of %s labels changed: %t annotations changed: %t", key, labelsChanged, annotationsChanged)
+
+	oldData, err := json.Marshal(workload)
+	if err != nil {
+		return err
+	}
+
+	template.Labels = newPod.Labels
+	template.Annotations = newPod.Annotations
+	newData, err := json.Marshal(workload)
+	if err != nil {
+		return err
+	}
+
+	patchBytes, err := strategicpatch.CreateTwoWayMergePatch(oldData, newData, workload)
+	if err != nil {
+		return err
+	}
+
+	if *plc.dryRun {
+		auditedPatches.Inc()
+		log.Printf("Dry run: pod template of %s would be patched with %s", key, patchBytes)
+		plc.recorder.Eventf(workload, corev1.EventTypeNormal, "AuditPatch", "Dry run, the pod template would be patched with %s", patchBytes)
+		return nil
+	}
+
+	err = plc.patchWorkload(kind, namespace, name, patchBytes)
+	owners := patchOwners(pod, newPod)
+	if err != nil {
+		plc.recorder.Eventf(workload, corev1.EventTypeWarning, "PatchFailed", "Error patching pod template labels and annotations from %s: %s", strings.Join(owners, ", "), err)
+		plc.recordConfigEvents(pod, owners, corev1.EventTypeWarning, "PatchFailed", fmt.Sprintf("Error patching pod template of %s: %s", key, err))
+		return err
+	}
+
+	plc.recorder.Eventf(workload, corev1.EventTypeNormal, "Patched", "Patched pod template labels and annotations from %s: %s", strings.Join(owners, ", "), patchBytes)
+	plc.recordConfigEvents(pod, owners, corev1.EventTypeNormal, "PatchedTemplate", fmt.Sprintf("Patched pod template of %s", key))
+	return nil
+}
+
+// isExcluded reports if a pod is never labelled, by the exclusion rules and the opt-out annotations
+func (plc *PodLabelController) isExcluded(pod *corev1.Pod) bool {
+	return plc.excluded.Excluded(pod, plc.namespaceLister.Get, plc.topLevelOwner)
+}
+
+// needsStripping reports if an excluded pod still has keys set by configs, which are removed as if no config
+// matched it anymore. Pods in an excluded phase have finished and keep their keys
+func (plc *PodLabelController) needsStripping(pod *corev1.Pod) bool {
+	return labeling.HasManagedKeys(pod) && !plc.excluded.Phases[pod.Status.Phase]
+}
+
+// isExcludedWorkload reports if the pod template of a workload is never labelled
+func (plc *PodLabelController) isExcludedWorkload(kind string, pod *corev1.Pod) bool {
+	return plc.excluded.OwnerKinds[kind] || plc.isExcluded(pod)
 }
 
 func (plc *PodLabelController) StartQueueWorkers(threadiness int, stopCh chan struct{}) {
@@ -133,19 +789,56 @@ func (plc *PodLabelController) StartQueueWorkers(threadiness int, stopCh chan st
 
 	// Let the workers stop when we are done
 	defer plc.podQueue.ShutDown()
+	defer plc.workloadQueue.ShutDown()
+	defer plc.resourceQueue.ShutDown()
+	defer plc.configs.Queue.ShutDown()
+	defer plc.clusterConfigs.Queue.ShutDown()
+	defer plc.resourceConfigs.Queue.ShutDown()
 	log.Println("Starting Pod Queue Workers")
 
-	go plc.podInformer.Run(stopCh)
+	// The pod informer is already synced by Run, pods queued until now are processed first
+	atomic.StoreInt64(&plc.lastPodProcessed, time.Now().Unix())
+	for i := 0; i < threadiness; i++ {
+		plc.workers.Add(1)
+		go func() {
+			defer plc.workers.Done()
+			// Once the queue is shut down the worker finishes its current item and returns
+			wait.Until(plc.runPodQueueWorker, time.Second, stopCh)
+		}()
+
+		plc.workers.Add(1)
+		go func() {
+			defer plc.workers.Done()
+			wait.Until(plc.runWorkloadQueueWorker, time.Second, stopCh)
+		}()
 
-	// Wait for all involved caches to be synced, before processing items from the queue is started
-	if !cache.WaitForCacheSync(stopCh, plc.podInformer.HasSynced) {
-		runtime.HandleError(fmt.Errorf("Timed out waiting for caches to sync"))
-		return
+		plc.workers.Add(1)
+		go func() {
+			defer plc.workers.Done()
+			wait.Until(plc.runResourceQueueWorker, time.Second, stopCh)
+		}()
 	}
 
-	for i := 0; i < threadiness; i++ {
-		go wait.Until(plc.runPodQueueWorker, time.Second, stopCh)
-	}
+	// Status writes are few, a single worker keeps the updates of a namespace from racing each other
+	plc.workers.Add(1)
+	go func() {
+		defer plc.workers.Done()
+		wait.Until(plc.runConfigQueueWorker, time.Second, stopCh)
+	}()
+
+	// Cluster-scoped configs only get their finalizers written, a single worker each is enough
+	plc.workers.Add(1)
+	go func() {
+		defer plc.workers.Done()
+		wait.Until(plc.runClusterConfigQueueWorker, time.Second, stopCh)
+	}()
+
+	plc.workers.Add(1)
+	go func() {
+		defer plc.workers.Done()
+		wait.Until(plc.runResourceConfigQueueWorker, time.Second, stopCh)
+	}()
+	atomic.StoreInt32(&plc.podsSynced, 1)
 
 	<-stopCh
 	log.Println("Stopping Pod Queue Workers")
@@ -162,15 +855,25 @@ func (plc *PodLabelController) processNextPod() bool {
 	if quit {
 		return false
 	}
+	// Stop taking new items on shutdown, they are listed again by the informer on the next start
+	if plc.podQueue.ShuttingDown() {
+		plc.podQueue.Done(key)
+		return false
+	}
 	// Tell the queue that we are done with processing this key. This unblocks the key for other workers
 	// This allows safe parallel processing because two pods with the same key are never processed in
 	// parallel.
 	defer plc.podQueue.Done(key)
 
+	// Queued configs wait for the pods in flight before they count them
+	atomic.AddInt32(&plc.podsProcessing, 1)
+	defer atomic.AddInt32(&plc.podsProcessing, -1)
+
 	// Invoke the method containing the business logic
 	err := plc.processPod(key.(string))
 	// Handle the error if something went wrong during the execution of the business logic
-	plc.handleErr(err, key)
+	plc.handleErr(plc.podQueue, err, key)
+	atomic.StoreInt64(&plc.lastPodProcessed, time.Now().Unix())
 	return true
 }
 
@@ -181,153 +884,959 @@ func (plc *PodLabelController) processPod(key string) error {
 		return err
 	}
 
-	if exists {
-		plc.handlePod(obj.(*corev1.Pod))
+	if !exists {
+		return nil
 	}
 
-	return nil
+	// The pod or its namespace may have opted out since the pod was queued. Excluded pods get the keys
+	// configs set before removed, as if no config matched them anymore
+	pod := obj.(*corev1.Pod)
+	configs := []labeling.Source{}
+	if plc.isExcluded(pod) {
+		if !plc.needsStripping(pod) {
+			return nil
+		}
+	} else {
+		configs = plc.matchingConfigs(pod)
+	}
+
+	// Errors are returned so the pod is retried with backoff
+	_, err = plc.handlePod(pod, configs)
+
+	// A conflict only resolves once the other field manager gives up the key or force is enabled. The pod
+	// informer never resyncs, so the pod is retried with a slower backoff of its own and never dropped
+	if plc.apply != nil && errors.IsConflict(err) {
+		plc.podQueue.AddAfter(key, plc.applyConflictBackoff.When(key))
+		return nil
+	}
+	plc.applyConflictBackoff.Forget(key)
+	return err
 }
 
 // handleErr checks if an error happened and makes sure we will retry later.
-func (plc *PodLabelController) handleErr(err error, key interface{}) {
+func (plc *PodLabelController) handleErr(queue workqueue.RateLimitingInterface, err error, key interface{}) {
 	if err == nil {
 		// Forget about the #AddRateLimited history of the key on every successful synchronization.
 		// This ensures that future processing of updates for this key is not delayed because of
 		// an outdated error history.
-		plc.podQueue.Forget(key)
+		queue.Forget(key)
 		return
 	}
 
 	// This controller retries 5 times if something goes wrong. After that, it stops trying.
-	if plc.podQueue.NumRequeues(key) < 5 {
-		log.Printf("Error syncing pod %v: %v\n", key, err)
+	if queue.NumRequeues(key) < 5 {
+		log.Printf("Error syncing %v: %v\n", key, err)
 
 		// Re-enqueue the key rate limited. Based on the rate limiter on the
 		// queue and the re-enqueue history, the key will be processed later again.
-		plc.podQueue.AddRateLimited(key)
+		queue.AddRateLimited(key)
 		return
 	}
 
-	plc.podQueue.Forget(key)
+	queue.Forget(key)
 	// Report to an external entity that, even after several retries, we could not successfully process this key
 	runtime.HandleError(err)
-	log.Printf("Dropping pod %q out of the queue: %v\n", key, err)
+	droppedKeys.Inc()
+	log.Printf("Dropping %q out of the queue: %v\n", key, err)
 }
 
-func (plc *PodLabelController) handlePod(pod *corev1.Pod) error {
+// handlePod applies the configs to a pod and reports if the pod had to be patched
+func (plc *PodLabelController) handlePod(pod *corev1.Pod, configs []labeling.Source) (bool, error) {
 	o, err := machinery_runtime.NewScheme().DeepCopy(pod)
 	if err != nil {
-		return err
+		return false, err
 	}
 	newPod := o.(*corev1.Pod)
 
-	// apply labels if needed
+	// apply labels and annotations if needed
 	// if no changes then return
-	if !plc.labelPod(newPod) {
-		return nil
+	labelsChanged := plc.labelPod(newPod, configs)
+	annotationsChanged := plc.annotatePod(newPod, configs)
+
+	// Report what the configs in audit mode would change on top of the enforced ones. In dry-run mode every
+	// config is audited, the unchanged pod is audited instead so the one report includes the keys of deleted
+	// configs that would be removed, and nothing is written
+	if *plc.dryRun {
+		if err := plc.auditPod(pod, configs); err != nil {
+			log.Printf("Error auditing pod %s/%s: %s", pod.GetNamespace(), pod.GetName(), err)
+		}
+		plc.reportMismatches(pod, configs)
+		return false, nil
+	}
+	if err := plc.auditPod(newPod, configs); err != nil {
+		log.Printf("Error auditing pod %s/%s: %s", pod.GetNamespace(), pod.GetName(), err)
+	}
+	plc.reportMismatches(pod, configs)
+
+	if !labelsChanged && !annotationsChanged {
+		return false, nil
 	}
 
+	log.Printf("Patching pod %s/%s labels changed: %t annotations changed: %t", pod.GetNamespace(), pod.GetName(), labelsChanged, annotationsChanged)
+
 	// Uncomment to test threaded queue
 	// log.Printf("Long operation on %s starting\n", pod.GetName())
 	// time.Sleep(time.Second * 3)
 	// log.Printf("Long operation on %s done\n", pod.GetName())
 
+	var patchBytes []byte
+	if plc.apply != nil {
+		patchBytes, err = plc.applyPod(newPod)
+	} else {
+		patchBytes, err = plc.patchPod(pod, newPod)
+	}
+	owners := patchOwners(pod, newPod)
+
+	// Another field manager owns one of the keys with a different value. The conflict is returned so
+	// processPod retries the pod with the conflict backoff
+	if plc.apply != nil && errors.IsConflict(err) {
+		applyConflicts.Inc()
+		log.Printf("Conflict applying pod %s/%s: %s", pod.GetNamespace(), pod.GetName(), err)
+		plc.recorder.Eventf(pod, corev1.EventTypeWarning, "ApplyConflict", "Labels and annotations from %s are owned by another field manager: %s", strings.Join(owners, ", "), err)
+		plc.recordConfigEvents(pod, owners, corev1.EventTypeWarning, "ApplyConflict", fmt.Sprintf("Keys on pod %s/%s are owned by another field manager: %s", pod.GetNamespace(), pod.GetName(), err))
+		return false, err
+	}
+	if err != nil {
+		podPatchFailures.Inc()
+		plc.recorder.Eventf(pod, corev1.EventTypeWarning, "PatchFailed", "Error patching labels and annotations from %s: %s", strings.Join(owners, ", "), err)
+		plc.recordConfigEvents(pod, owners, corev1.EventTypeWarning, "PatchFailed", fmt.Sprintf("Error patching pod %s/%s: %s", pod.GetNamespace(), pod.GetName(), err))
+		return false, err
+	}
+
+	podPatches.Inc()
+	plc.recorder.Eventf(pod, corev1.EventTypeNormal, "Patched", "Patched labels and annotations from %s: %s", strings.Join(owners, ", "), patchBytes)
+	plc.recordConfigEvents(pod, owners, corev1.EventTypeNormal, "PatchedPod", fmt.Sprintf("Patched pod %s/%s", pod.GetNamespace(), pod.GetName()))
+	return true, nil
+}
+
+// podPatch returns the strategic merge patch with the changes between the pod and the labelled pod
+func podPatch(pod, newPod *corev1.Pod) ([]byte, error) {
 	oldData, err := json.Marshal(pod)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	newData, err := json.Marshal(newPod)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	patchBytes, err := strategicpatch.CreateTwoWayMergePatch(oldData, newData, corev1.Pod{})
+	return strategicpatch.CreateTwoWayMergePatch(oldData, newData, corev1.Pod{})
+}
+
+// patchPod sends a strategic merge patch with the changes between the pod and the labelled pod
+func (plc *PodLabelController) patchPod(pod, newPod *corev1.Pod) ([]byte, error) {
+	patchBytes, err := podPatch(pod, newPod)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
+	plc.writeLimiter.Accept()
 	_, err = plc.client.CoreV1().Pods(pod.Namespace).Patch(pod.Name, types.StrategicMergePatchType, patchBytes)
+	return patchBytes, err
+}
+
+// serverSideApply holds the settings used to apply the labels and annotations of pods
+type serverSideApply struct {
+	// fieldManager owns every applied key
+	fieldManager string
+	// force takes over keys owned by other field managers instead of failing with a conflict
+	force bool
+}
+
+// applyPod applies the labels and annotations the configs manage on the labelled pod, and the tracking annotations,
+// with server-side apply. Keys which the field manager applied before but which are left out now are removed by the
+// apiserver, unless another manager owns them too.
+func (plc *PodLabelController) applyPod(pod *corev1.Pod) ([]byte, error) {
+	annotations := managedValues(pod, pod.GetAnnotations(), labeling.ManagedAnnotationsAnnotation)
+	for _, a := range []string{labeling.ManagedLabelsAnnotation, labeling.ManagedAnnotationsAnnotation} {
+		if v, ok := pod.GetAnnotations()[a]; ok {
+			annotations[a] = v
+		}
+	}
+
+	applied := &unstructured.Unstructured{}
+	applied.SetAPIVersion("v1")
+	applied.SetKind("Pod")
+	applied.SetName(pod.GetName())
+	applied.SetNamespace(pod.GetNamespace())
+	applied.SetLabels(managedValues(pod, pod.GetLabels(), labeling.ManagedLabelsAnnotation))
+	applied.SetAnnotations(annotations)
+
+	data, err := applied.MarshalJSON()
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	return nil
+	req := plc.client.CoreV1().RESTClient().Patch(ApplyPatchType).
+		Namespace(pod.GetNamespace()).
+		Resource("pods").
+		Name(pod.GetName()).
+		Param("fieldManager", plc.apply.fieldManager)
+	if plc.apply.force {
+		req = req.Param("force", "true")
+	}
+
+	plc.writeLimiter.Accept()
+	return data, req.Body(data).Do().Error()
 }
 
-func (plc *PodLabelController) labelPod(pod *corev1.Pod) bool {
-	changed := false
-	// make sure map is initialized
-	if len(pod.GetLabels()) == 0 {
-		pod.ObjectMeta.Labels = make(map[string]string)
+// managedValues returns the entries of m whose keys a config manages according to the tracking annotation
+func managedValues(pod *corev1.Pod, m map[string]string, annotation string) map[string]string {
+	values := make(map[string]string)
+	for _, keys := range labeling.ManagedKeys(pod, annotation) {
+		for _, k := range keys {
+			if v, ok := m[k]; ok {
+				values[k] = v
+			}
+		}
 	}
+	return values
+}
 
-	// Loop all configs
-	for _, obj := range plc.podLabelConfigStore.List() {
-		c := obj.(*plv1alpha1.PodLabelConfig)
-		// only apply labels if namespace matches
-		if pod.GetNamespace() == c.GetNamespace() {
-			// check keys
-			for k, newVal := range c.Spec.Labels {
-				if curVal, ok := pod.GetLabels()[k]; ok && curVal == newVal {
-					// log.Printf("Pod %s already has label: %s=%s", pod.GetName(), k, newVal)
-				} else {
-					log.Printf("Pod %s needs label: %s=%s", pod.GetName(), k, newVal)
-					pod.Labels[k] = newVal
-					changed = true
+// patchOwners returns the configs which own a label or annotation that differs between the pod and the patched pod.
+// Keys removed for deleted configs have no owner anymore.
+func patchOwners(pod, newPod *corev1.Pod) []string {
+	owned := make(map[string]bool)
+	for _, keys := range []struct {
+		annotation string
+		old, new   map[string]string
+	}{
+		{labeling.ManagedLabelsAnnotation, pod.GetLabels(), newPod.GetLabels()},
+		{labeling.ManagedAnnotationsAnnotation, pod.GetAnnotations(), newPod.GetAnnotations()},
+	} {
+		for owner, managed := range labeling.ManagedKeys(newPod, keys.annotation) {
+			for _, k := range managed {
+				oldVal, ok := keys.old[k]
+				if !ok || oldVal != keys.new[k] {
+					owned[owner] = true
 				}
 			}
 		}
 	}
-	return changed
-}
 
-func (plc *PodLabelController) ReconcileAllPods(c *plv1alpha1.PodLabelConfig) {
-	// Only reconcile after initial sync
-	if !plc.HasSynced {
-		return
+	owners := make([]string, 0, len(owned))
+	for owner := range owned {
+		owners = append(owners, owner)
 	}
+	sort.Strings(owners)
+	return owners
+}
 
-	log.Printf("Reconciling of all pods for plc: %s\n", c.GetNamespace())
-	pods, err := plc.client.CoreV1().Pods(c.GetNamespace()).List(metav1.ListOptions{})
-	if err != nil {
-		log.Println(err)
-	}
-	for _, p := range pods.Items {
-		if err := plc.handlePod(&p); err != nil {
-			log.Printf("Error handling pod: %s", err)
+// recordConfigEvents records an event on every PodLabelConfig, ClusterPodLabelConfig and ResourceLabelConfig in owners
+func (plc *PodLabelController) recordConfigEvents(pod *corev1.Pod, owners []string, eventType, reason, message string) {
+	for _, owner := range owners {
+		var obj machinery_runtime.Object
+		var err error
+		if strings.HasPrefix(owner, labeling.ClusterOwnerPrefix) {
+			obj, err = plc.clusterPodLabelConfigLister.Get(strings.TrimPrefix(owner, labeling.ClusterOwnerPrefix))
+		} else if strings.HasPrefix(owner, labeling.ResourceOwnerPrefix) {
+			obj, err = plc.resourceLabelConfigLister.Get(strings.TrimPrefix(owner, labeling.ResourceOwnerPrefix))
+		} else {
+			obj, err = plc.podLabelConfigLister.PodLabelConfigs(pod.GetNamespace()).Get(owner)
 		}
+		if err != nil {
+			continue
+		}
+		plc.recorder.Event(obj, eventType, reason, message)
 	}
 }
 
-func (plc *PodLabelController) StartPodLabelConfigController(killChan chan struct{}) {
-	log.Print("Starting PodLabelConfig Controller")
+// StartMetricsServer serves the prometheus metrics on /metrics until the server fails
+func (plc *PodLabelController) StartMetricsServer(addr string) {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
 
-	restClient := plc.plClientset.PodlabelerV1alpha1().RESTClient()
-	listwatch := cache.NewListWatchFromClient(restClient, "podlabelconfigs", corev1.NamespaceAll, fields.Everything())
+	log.Printf("Starting metrics server on %s", addr)
+	if err := http.ListenAndServe(addr, mux); err != nil {
+		log.Fatalf("Metrics server failed: %s", err)
+	}
+}
 
-	plc.podLabelConfigStore, plc.podLabelConfigController = cache.NewInformer(listwatch, &plv1alpha1.PodLabelConfig{}, 0,
-		cache.ResourceEventHandlerFuncs{
+// StartHealthServer serves the /healthz liveness and /readyz readiness probes until the server fails.
+// The controller is considered dead when no pod was processed for livenessTimeout while pods are queued,
+// or when no namespace event was seen for livenessTimeout.
+func (plc *PodLabelController) StartHealthServer(addr string, livenessTimeout time.Duration) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		if err := plc.checkLiveness(livenessTimeout); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		fmt.Fprint(w, "ok")
+	})
+	mux.HandleFunc("/readyz", func(w http.ResponseWriter, r *http.Request) {
+		if !plc.HasSynced || atomic.LoadInt32(&plc.podsSynced) == 0 {
+			http.Error(w, "caches are not synced", http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprint(w, "ok")
+	})
+
+	log.Printf("Starting health server on %s", addr)
+	if err := http.ListenAndServe(addr, mux); err != nil {
+		log.Fatalf("Health server failed: %s", err)
+	}
+}
+
+// checkLiveness returns an error if the pod workers are wedged or the namespace watch is broken.
+// Nothing is checked before the caches are synced, that is covered by the readiness probe.
+func (plc *PodLabelController) checkLiveness(timeout time.Duration) error {
+	if atomic.LoadInt32(&plc.podsSynced) == 0 {
+		return nil
+	}
+
+	lastProcessed := time.Unix(atomic.LoadInt64(&plc.lastPodProcessed), 0)
+	if plc.podQueue.Len() > 0 && time.Since(lastProcessed) > timeout {
+		return fmt.Errorf("no pod processed since %s with %d pods queued", lastProcessed, plc.podQueue.Len())
+	}
+
+	lastEvent := time.Unix(atomic.LoadInt64(&plc.lastNamespaceEvent), 0)
+	if time.Since(lastEvent) > timeout {
+		return fmt.Errorf("no namespace event or resync since %s, the watch is broken", lastEvent)
+	}
+	return nil
+}
+
+// StartWebhookServer serves the admission webhooks and the PodLabelConfig conversion webhook until the server fails
+func (plc *PodLabelController) StartWebhookServer(addr, certFile, keyFile string) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/mutate-pods", webhook.Serve(plc.admitPod))
+	mux.HandleFunc("/validate-podlabelconfigs", webhook.Serve(plc.admitPodLabelConfig))
+	mux.HandleFunc("/convert-podlabelconfigs", webhook.ServeConversion(plv1beta1.ConvertPodLabelConfig))
+
+	log.Printf("Starting webhook server on %s", addr)
+	if err := http.ListenAndServeTLS(addr, certFile, keyFile, mux); err != nil {
+		log.Fatalf("Webhook server failed: %s", err)
+	}
+}
+
+// admitPod adds the labels and annotations from the matching configs to a pod before it is created.
+// Pods are always admitted, anything missed here is still patched by the pod controller.
+func (plc *PodLabelController) admitPod(req *webhook.AdmissionRequest) *webhook.AdmissionResponse {
+	// Without a synced store the pod would be admitted with missing keys anyway, leave it to the pod controller
+	if !plc.HasSynced || req.Operation != webhook.Create {
+		return webhook.Allowed()
+	}
+
+	pod := &corev1.Pod{}
+	if err := json.Unmarshal(req.Object.Raw, pod); err != nil {
+		log.Printf("Error decoding pod from admission request: %s", err)
+		return webhook.Allowed()
+	}
+
+	// The namespace is not always set on the object in the request
+	if pod.GetNamespace() == "" {
+		pod.SetNamespace(req.Namespace)
+	}
+
+	if plc.isExcluded(pod) {
+		return webhook.Allowed()
+	}
+
+	o, err := machinery_runtime.NewScheme().DeepCopy(pod)
+	if err != nil {
+		log.Printf("Error copying pod from admission request: %s", err)
+		return webhook.Allowed()
+	}
+	newPod := o.(*corev1.Pod)
+
+	// Templated labels are rendered by the controller once the pod is scheduled and running
+	configs := labeling.AdmissionSources(plc.matchingConfigs(pod))
+	labelsChanged := plc.labelPod(newPod, configs)
+	annotationsChanged := plc.annotatePod(newPod, configs)
+
+	// Compare the maps instead of trusting the changed flags, labelPod also writes the label tracking annotation
+	ops := webhook.MetadataPatch(pod, newPod)
+	if len(ops) > 0 {
+		// Pods created by controllers only have a generateName at this point
+		name := pod.GetName()
+		if name == "" {
+			name = pod.GetGenerateName()
+		}
+		log.Printf("Admitting pod %s/%s labels changed: %t annotations changed: %t", pod.GetNamespace(), name, labelsChanged, annotationsChanged)
+	}
+
+	response, err := webhook.Patched(ops)
+	if err != nil {
+		log.Printf("Error creating admission patch: %s", err)
+		return webhook.Allowed()
+	}
+	return response
+}
+
+// admitPodLabelConfig rejects PodLabelConfigs which the controller would fail to apply to pods
+func (plc *PodLabelController) admitPodLabelConfig(req *webhook.AdmissionRequest) *webhook.AdmissionResponse {
+	if req.Operation != webhook.Create && req.Operation != webhook.Update {
+		return webhook.Allowed()
+	}
+
+	c, err := decodePodLabelConfig(req.Object.Raw)
+	if err != nil {
+		return webhook.Denied(http.StatusBadRequest, fmt.Sprintf("could not decode PodLabelConfig: %s", err))
+	}
+
+	if c.GetNamespace() == "" {
+		c.SetNamespace(req.Namespace)
+	}
+
+	errs, warnings := plc.validatePodLabelConfig(c)
+	if len(errs) > 0 {
+		log.Printf("Rejecting PodLabelConfig %s/%s: %s", c.GetNamespace(), c.GetName(), strings.Join(errs, ", "))
+		return webhook.Denied(http.StatusUnprocessableEntity, fmt.Sprintf("PodLabelConfig %s/%s is invalid: %s", c.GetNamespace(), c.GetName(), strings.Join(errs, ", ")))
+	}
+
+	response := webhook.Allowed()
+	if len(warnings) > 0 {
+		log.Printf("Admitting PodLabelConfig %s/%s with overridden keys: %s", c.GetNamespace(), c.GetName(), strings.Join(warnings, ", "))
+		response.Warnings = warnings
+	}
+	return response
+}
+
+// decodePodLabelConfig decodes a PodLabelConfig of any served version into v1alpha1, the version the controller works with
+func decodePodLabelConfig(raw []byte) (*plv1alpha1.PodLabelConfig, error) {
+	converted, err := plv1beta1.ConvertPodLabelConfig(machinery_runtime.RawExtension{Raw: raw}, plv1alpha1.SchemeGroupVersion.String())
+	if err != nil {
+		return nil, err
+	}
+
+	c := &plv1alpha1.PodLabelConfig{}
+	if err := json.Unmarshal(converted.Raw, c); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// validatePodLabelConfig returns a description of every problem with a config, and warnings for the keys of the
+// config that are set to a different value by configs with a higher priority. Only conflicts between configs with
+// the same priority are rejected. Overridden keys are a valid way to set defaults, they are admitted with a warning
+// and reported by the Conflicting condition of the config.
+func (plc *PodLabelController) validatePodLabelConfig(c *plv1alpha1.PodLabelConfig) ([]string, []string) {
+	errs := webhook.ValidatePodLabelConfigSpec(&c.Spec)
+	if !plc.HasSynced {
+		return errs, nil
+	}
+
+	others, err := plc.podLabelConfigLister.PodLabelConfigs(c.GetNamespace()).List(labels.Everything())
+	if err != nil {
+		errs = append(errs, fmt.Sprintf("could not list PodLabelConfigs: %s", err))
+	}
+	overriding := []labeling.Source{}
+	for _, other := range others {
+		if other.GetName() == c.GetName() || other.GetDeletionTimestamp() != nil {
+			continue
+		}
+		if other.Spec.Priority != c.Spec.Priority {
+			overriding = append(overriding, labeling.NamespacedSource(other))
+			continue
+		}
+
+		// Configs with equal priority would be ordered by name only, make the precedence explicit instead
+		for _, conflict := range labeling.FindConflicts(labeling.NamespacedSource(c), []labeling.Source{labeling.NamespacedSource(other)}) {
+			errs = append(errs, conflict+" with the same priority")
+		}
+		for _, conflict := range labeling.FindConflicts(labeling.NamespacedSource(other), []labeling.Source{labeling.NamespacedSource(c)}) {
+			errs = append(errs, conflict+" with the same priority")
+		}
+	}
+
+	clusterConfigs, err := plc.clusterPodLabelConfigLister.List(labels.Everything())
+	if err != nil {
+		errs = append(errs, fmt.Sprintf("could not list ClusterPodLabelConfigs: %s", err))
+	}
+	for _, other := range clusterConfigs {
+		if other.GetDeletionTimestamp() == nil && selectors.ClusterConfigMatches(plc.namespaceLister, other, c.GetNamespace()) {
+			overriding = append(overriding, labeling.ClusterSource(other))
+		}
+	}
+
+	return errs, labeling.FindConflicts(labeling.NamespacedSource(c), overriding)
+}
+
+// auditPod logs and records an event for the patch that would be made if all configs in audit mode
+// were enforced. The pod itself is never changed. In dry-run mode it reports the whole patch the pod would get.
+func (plc *PodLabelController) auditPod(pod *corev1.Pod, configs []labeling.Source) error {
+	enforced := make([]labeling.Source, len(configs))
+	audited := false
+	for i, c := range configs {
+		audited = audited || c.Audit
+		c.Audit = false
+		enforced[i] = c
+	}
+	// In dry-run mode the keys of deleted configs and excluded pods would be removed too
+	if !audited && !*plc.dryRun {
+		return nil
+	}
+
+	o, err := machinery_runtime.NewScheme().DeepCopy(pod)
+	if err != nil {
+		return err
+	}
+	auditedPod := o.(*corev1.Pod)
+
+	labelsChanged := plc.labelPod(auditedPod, enforced)
+	annotationsChanged := plc.annotatePod(auditedPod, enforced)
+	if !labelsChanged && !annotationsChanged {
+		return nil
+	}
+
+	patchBytes, err := podPatch(pod, auditedPod)
+	if err != nil {
+		return err
+	}
+
+	auditedPatches.Inc()
+	log.Printf("Audit: pod %s/%s would be patched with %s", pod.GetNamespace(), pod.GetName(), patchBytes)
+	plc.recorder.Eventf(pod, corev1.EventTypeNormal, "AuditPatch", "PodLabelConfigs in audit mode would patch the pod with %s", patchBytes)
+	return nil
+}
+
+// matchingConfigs returns all PodLabelConfigs and ClusterPodLabelConfigs that target the given pod
+func (plc *PodLabelController) matchingConfigs(pod *corev1.Pod) []labeling.Source {
+	sources := []labeling.Source{}
+	configs, err := plc.podLabelConfigLister.PodLabelConfigs(pod.GetNamespace()).List(labels.Everything())
+	if err != nil {
+		log.Printf("Error listing PodLabelConfigs: %s", err)
+	}
+	for _, c := range configs {
+		// configs being deleted no longer apply, their keys are removed instead
+		if c.GetDeletionTimestamp() != nil {
+			continue
+		}
+		// only apply if selector matches
+		if selectors.PodMatches(labeling.NamespacedSource(c), labels.Set(pod.GetLabels())) {
+			sources = append(sources, labeling.NamespacedSource(c))
+		}
+	}
+
+	clusterConfigs, err := plc.clusterPodLabelConfigLister.List(labels.Everything())
+	if err != nil {
+		log.Printf("Error listing ClusterPodLabelConfigs: %s", err)
+	}
+	for _, c := range clusterConfigs {
+		if c.GetDeletionTimestamp() != nil {
+			continue
+		}
+		// only apply if namespace selector and pod selector match
+		if selectors.ClusterConfigMatches(plc.namespaceLister, c, pod.GetNamespace()) && selectors.PodMatches(labeling.ClusterSource(c), labels.Set(pod.GetLabels())) {
+			sources = append(sources, labeling.ClusterSource(c))
+		}
+	}
+
+	// In dry-run mode every config is audited
+	if *plc.dryRun {
+		for i := range sources {
+			sources[i].Audit = true
+		}
+	}
+
+	// Order by precedence so conflicting keys are always resolved the same way
+	sort.Slice(sources, func(i, j int) bool {
+		return labeling.HasPrecedence(sources[i], sources[j])
+	})
+	return sources
+}
+
+func (plc *PodLabelController) labelPod(pod *corev1.Pod, configs []labeling.Source) bool {
+	changed := false
+	// make sure maps are initialized
+	if len(pod.GetLabels()) == 0 {
+		pod.ObjectMeta.Labels = make(map[string]string)
+	}
+	if len(pod.GetAnnotations()) == 0 {
+		pod.ObjectMeta.Annotations = make(map[string]string)
+	}
+
+	values, owners, audited := labeling.ResolveKeys(configs, func(spec *plv1alpha1.PodLabelConfigSpec) map[string]string {
+		return spec.Labels
+	})
+	labeling.KeepAuditedKeys(pod, labeling.ManagedLabelsAnnotation, configs, audited, owners)
+	for k := range labeling.PresentKeys(pod, pod.Labels, labeling.ManagedLabelsAnnotation, configs, owners) {
+		delete(values, k)
+	}
+
+	// Remove labels which were set by a config but are no longer wanted by any config
+	if labeling.ReconcileManagedKeys(pod, "label", pod.Labels, labeling.ManagedLabelsAnnotation, owners) {
+		changed = true
+	}
+
+	// check keys
+	for k, newVal := range values {
+		// Templated values are evaluated against the pod
+		newVal, err := templates.Render(pod, plc.topLevelOwner, newVal)
+		if err != nil {
+			log.Printf("Skipping label %s for pod %s: %s", k, pod.GetName(), err)
+			continue
+		}
+
+		if curVal, ok := pod.GetLabels()[k]; ok && curVal == newVal {
+			// log.Printf("Pod %s already has label: %s=%s", pod.GetName(), k, newVal)
+		} else {
+			log.Printf("Pod %s needs label: %s=%s", pod.GetName(), k, newVal)
+			pod.Labels[k] = newVal
+			changed = true
+		}
+	}
+	return changed
+}
+
+func (plc *PodLabelController) annotatePod(pod *corev1.Pod, configs []labeling.Source) bool {
+	changed := false
+	// make sure map is initialized
+	if len(pod.GetAnnotations()) == 0 {
+		pod.ObjectMeta.Annotations = make(map[string]string)
+	}
+
+	values, owners, audited := labeling.ResolveKeys(configs, func(spec *plv1alpha1.PodLabelConfigSpec) map[string]string {
+		return spec.Annotations
+	})
+	labeling.KeepAuditedKeys(pod, labeling.ManagedAnnotationsAnnotation, configs, audited, owners)
+	for k := range labeling.PresentKeys(pod, pod.Annotations, labeling.ManagedAnnotationsAnnotation, configs, owners) {
+		delete(values, k)
+	}
+
+	// Remove annotations which were set by a config but are no longer wanted by any config
+	if labeling.ReconcileManagedKeys(pod, "annotation", pod.Annotations, labeling.ManagedAnnotationsAnnotation, owners) {
+		changed = true
+	}
+
+	// check keys
+	for k, newVal := range values {
+		if curVal, ok := pod.GetAnnotations()[k]; ok && curVal == newVal {
+			// log.Printf("Pod %s already has annotation: %s=%s", pod.GetName(), k, newVal)
+		} else {
+			log.Printf("Pod %s needs annotation: %s=%s", pod.GetName(), k, newVal)
+			pod.Annotations[k] = newVal
+			changed = true
+		}
+	}
+	return changed
+}
+
+// topLevelOwner follows the controller references of a pod up to the object that manages it,
+// for example Pod -> ReplicaSet -> Deployment. Pods without a controller are their own owner.
+func (plc *PodLabelController) topLevelOwner(pod *corev1.Pod) (string, string, error) {
+	kind, name := "Pod", pod.GetName()
+	ref := metav1.GetControllerOf(pod)
+	for ref != nil {
+		kind, name = ref.Kind, ref.Name
+
+		// Only ReplicaSets and Jobs are commonly owned by another controller
+		var owner metav1.Object
+		var err error
+		switch kind {
+		case "ReplicaSet":
+			owner, err = plc.replicaSetLister.ReplicaSets(pod.GetNamespace()).Get(name)
+		case "Job":
+			owner, err = plc.jobLister.Jobs(pod.GetNamespace()).Get(name)
+		default:
+			return kind, name, nil
+		}
+		if err != nil {
+			return "", "", err
+		}
+		ref = metav1.GetControllerOf(owner)
+	}
+	return kind, name, nil
+}
+
+// reportMismatches logs and records an event for every key that a config in IfAbsent mode left alone
+// although the pod has a different value
+func (plc *PodLabelController) reportMismatches(pod *corev1.Pod, configs []labeling.Source) {
+	mismatches := []string{}
+	for _, keys := range []struct {
+		kind       string
+		annotation string
+		m          map[string]string
+		keysFunc   func(*plv1alpha1.PodLabelConfigSpec) map[string]string
+	}{
+		{"label", labeling.ManagedLabelsAnnotation, pod.GetLabels(), func(spec *plv1alpha1.PodLabelConfigSpec) map[string]string { return spec.Labels }},
+		{"annotation", labeling.ManagedAnnotationsAnnotation, pod.GetAnnotations(), func(spec *plv1alpha1.PodLabelConfigSpec) map[string]string { return spec.Annotations }},
+	} {
+		values, owners, _ := labeling.ResolveKeys(configs, keys.keysFunc)
+		present := labeling.PresentKeys(pod, keys.m, keys.annotation, configs, owners)
+		for _, k := range labeling.SortedKeys(present) {
+			want := values[k]
+			if keys.kind == "label" {
+				var err error
+				if want, err = templates.Render(pod, plc.topLevelOwner, want); err != nil {
+					continue
+				}
+			}
+			if keys.m[k] != want {
+				mismatches = append(mismatches, fmt.Sprintf("%s %s is %q, %s wants %q", keys.kind, k, keys.m[k], present[k], want))
+			}
+		}
+	}
+	if len(mismatches) == 0 {
+		return
+	}
+
+	log.Printf("Pod %s/%s keeps existing values: %s", pod.GetNamespace(), pod.GetName(), strings.Join(mismatches, ", "))
+	plc.recorder.Eventf(pod, corev1.EventTypeNormal, "KeptExisting", "PodLabelConfigs in IfAbsent mode left existing values in place: %s", strings.Join(mismatches, ", "))
+}
+
+func (plc *PodLabelController) runConfigQueueWorker() {
+	for plc.processNextConfig() {
+	}
+}
+
+func (plc *PodLabelController) processNextConfig() bool {
+	key, quit := plc.configs.Queue.Get()
+	if quit {
+		return false
+	}
+	// Stop taking new items on shutdown, they are listed again by the informers on the next start
+	if plc.configs.Queue.ShuttingDown() {
+		plc.configs.Queue.Done(key)
+		return false
+	}
+	defer plc.configs.Queue.Done(key)
+
+	// The pods of the config have to be processed before they are counted. Pods keep being queued on a busy
+	// cluster, so the config stops waiting after a few checks and its counts catch up on the next resync
+	if (plc.podQueue.Len() > 0 || plc.workloadQueue.Len() > 0 || atomic.LoadInt32(&plc.podsProcessing) > 0) &&
+		plc.statusDelay.NumRequeues(key) < 10 {
+		plc.configs.Queue.AddAfter(key, plc.statusDelay.When(key))
+		return true
+	}
+	plc.statusDelay.Forget(key)
+
+	err := plc.configs.Sync(key)
+	plc.handleErr(plc.configs.Queue, err, key)
+	return true
+}
+
+// processNextItem takes the next key off a queue and syncs it, retrying failures like the other queues
+func (plc *PodLabelController) processNextItem(queue workqueue.RateLimitingInterface, sync func(interface{}) error) bool {
+	key, quit := queue.Get()
+	if quit {
+		return false
+	}
+	// Stop taking new items on shutdown, they are listed again by the informers on the next start
+	if queue.ShuttingDown() {
+		queue.Done(key)
+		return false
+	}
+	defer queue.Done(key)
+
+	err := sync(key)
+	plc.handleErr(queue, err, key)
+	return true
+}
+
+func (plc *PodLabelController) runClusterConfigQueueWorker() {
+	for plc.processNextItem(plc.clusterConfigs.Queue, plc.clusterConfigs.Sync) {
+	}
+}
+
+// matchingResourceConfigs returns all ResourceLabelConfigs for a resource that target the given object
+func (plc *PodLabelController) matchingResourceConfigs(gvr schema.GroupVersionResource, obj *unstructured.Unstructured) []labeling.Source {
+	sources := []labeling.Source{}
+	configs, err := plc.resourceLabelConfigLister.List(labels.Everything())
+	if err != nil {
+		log.Printf("Error listing ResourceLabelConfigs: %s", err)
+	}
+	for _, c := range configs {
+		if c.GetDeletionTimestamp() != nil {
+			continue
+		}
+		if configGVR, err := resources.GVR(c); err != nil || configGVR != gvr {
+			continue
+		}
+
+		selector := labels.Everything()
+		if c.Spec.Selector != nil {
+			if selector, err = metav1.LabelSelectorAsSelector(c.Spec.Selector); err != nil {
+				log.Printf("Invalid selector in ResourceLabelConfig %s: %s", c.GetName(), err)
+				continue
+			}
+		}
+		if !selector.Matches(labels.Set(obj.GetLabels())) {
+			continue
+		}
+
+		// The namespace selector only applies to namespaced resources
+		if obj.GetNamespace() != "" && !selectors.NamespaceMatches(plc.namespaceLister, c.Spec.NamespaceSelector, "ResourceLabelConfig "+c.GetName(), obj.GetNamespace()) {
+			continue
+		}
+		sources = append(sources, labeling.ResourceSource(c))
+	}
+
+	if *plc.dryRun {
+		for i := range sources {
+			sources[i].Audit = true
+		}
+	}
+
+	sort.Slice(sources, func(i, j int) bool {
+		return labeling.HasPrecedence(sources[i], sources[j])
+	})
+	return sources
+}
+
+func (plc *PodLabelController) runResourceQueueWorker() {
+	for plc.processNextResource() {
+	}
+}
+
+func (plc *PodLabelController) processNextResource() bool {
+	key, quit := plc.resourceQueue.Get()
+	if quit {
+		return false
+	}
+	// Stop taking new items on shutdown, they are listed again by the informers on the next start
+	if plc.resourceQueue.ShuttingDown() {
+		plc.resourceQueue.Done(key)
+		return false
+	}
+	defer plc.resourceQueue.Done(key)
+
+	err := plc.handleResource(key.(resources.Key))
+	plc.handleErr(plc.resourceQueue, err, key)
+	return true
+}
+
+// handleResource applies the matching ResourceLabelConfigs to an object with a json merge patch, which works for
+// any resource including custom ones
+func (plc *PodLabelController) handleResource(key resources.Key) error {
+	w := plc.resourceWatches.Get(key.GVR)
+	if w == nil {
+		return nil
+	}
+
+	cached, exists, err := w.Informer.GetIndexer().GetByKey(key.Key)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return nil
+	}
+	obj := cached.(*unstructured.Unstructured)
+	if obj.GetDeletionTimestamp() != nil {
+		return nil
+	}
+
+	pod := resources.Pod(obj)
+	newPod := resources.Pod(obj)
+
+	// Excluded objects get the keys configs set before removed, like excluded pods
+	configs := []labeling.Source{}
+	if plc.isExcluded(pod) {
+		if !plc.needsStripping(pod) {
+			return nil
+		}
+	} else {
+		configs = plc.matchingResourceConfigs(key.GVR, obj)
+	}
+	labelsChanged := plc.labelPod(newPod, configs)
+	annotationsChanged := plc.annotatePod(newPod, configs)
+
+	// In dry-run mode the unchanged object is audited, like pods in handlePod, and nothing is written
+	if *plc.dryRun {
+		return plc.auditResource(obj, pod, configs)
+	}
+	if err := plc.auditResource(obj, newPod, configs); err != nil {
+		log.Printf("Error auditing %s: %s", key, err)
+	}
+
+	if !labelsChanged && !annotationsChanged {
+		return nil
+	}
+
+	log.Printf("Patching %s labels changed: %t annotations changed: %t", key, labelsChanged, annotationsChanged)
+
+	patchBytes, err := resources.MetadataMergePatch(pod, newPod)
+	if err != nil {
+		return err
+	}
+
+	plc.writeLimiter.Accept()
+	_, err = w.Client.Resource(w.APIResource, obj.GetNamespace()).Patch(obj.GetName(), types.MergePatchType, patchBytes)
+	owners := patchOwners(pod, newPod)
+	if err != nil {
+		plc.recorder.Eventf(obj, corev1.EventTypeWarning, "PatchFailed", "Error patching labels and annotations from %s: %s", strings.Join(owners, ", "), err)
+		plc.recordConfigEvents(pod, owners, corev1.EventTypeWarning, "PatchFailed", fmt.Sprintf("Error patching %s: %s", key, err))
+		return err
+	}
+
+	plc.recorder.Eventf(obj, corev1.EventTypeNormal, "Patched", "Patched labels and annotations from %s: %s", strings.Join(owners, ", "), patchBytes)
+	plc.recordConfigEvents(pod, owners, corev1.EventTypeNormal, "PatchedResource", fmt.Sprintf("Patched %s", key))
+	return nil
+}
+
+// auditResource logs and records an event for the patch the ResourceLabelConfigs in audit mode would make,
+// like auditPod. newPod holds the labels and annotations after the enforced configs were applied
+func (plc *PodLabelController) auditResource(obj *unstructured.Unstructured, newPod *corev1.Pod, configs []labeling.Source) error {
+	enforced := make([]labeling.Source, len(configs))
+	audited := false
+	for i, c := range configs {
+		audited = audited || c.Audit
+		c.Audit = false
+		enforced[i] = c
+	}
+	// In dry-run mode the keys of deleted configs and excluded pods would be removed too
+	if !audited && !*plc.dryRun {
+		return nil
+	}
+
+	auditedPod := newPod.DeepCopy()
+	labelsChanged := plc.labelPod(auditedPod, enforced)
+	annotationsChanged := plc.annotatePod(auditedPod, enforced)
+	if !labelsChanged && !annotationsChanged {
+		return nil
+	}
+
+	patchBytes, err := resources.MetadataMergePatch(newPod, auditedPod)
+	if err != nil {
+		return err
+	}
+
+	auditedPatches.Inc()
+	log.Printf("Audit: %s %s would be patched with %s", obj.GetKind(), newPod.GetName(), patchBytes)
+	plc.recorder.Eventf(obj, corev1.EventTypeNormal, "AuditPatch", "ResourceLabelConfigs in audit mode would patch the object with %s", patchBytes)
+	return nil
+}
+
+func (plc *PodLabelController) runResourceConfigQueueWorker() {
+	for plc.processNextItem(plc.resourceConfigs.Queue, plc.resourceConfigs.Sync) {
+	}
+}
+
+// StartNamespaceController watches namespaces so ClusterPodLabelConfigs can match on namespace labels.
+// The informer itself is started by Run.
+func (plc *PodLabelController) StartNamespaceController(killChan chan struct{}) {
+	log.Print("Starting Namespace Controller")
+
+	// There is always at least one namespace, so the resyncs double as a heartbeat for the liveness probe
+	plc.namespaceInformer.AddEventHandler(
+		cache.ResourceEventHandlerFuncs{
 			AddFunc: func(obj interface{}) {
-				log.Print("PodLabelConfig Add Event")
-				plc.ReconcileAllPods(obj.(*plv1alpha1.PodLabelConfig))
+				atomic.StoreInt64(&plc.lastNamespaceEvent, time.Now().Unix())
 			},
 			UpdateFunc: func(oldobj interface{}, newobj interface{}) {
-				log.Print("PodLabelConfig Update Event")
-				// Make sure object is not set for deltion and was actually changed
-				if newobj.(*plv1alpha1.PodLabelConfig).GetDeletionTimestamp() == nil &&
-					oldobj.(*plv1alpha1.PodLabelConfig).GetResourceVersion() != newobj.(*plv1alpha1.PodLabelConfig).GetResourceVersion() {
-					plc.ReconcileAllPods(newobj.(*plv1alpha1.PodLabelConfig))
+				atomic.StoreInt64(&plc.lastNamespaceEvent, time.Now().Unix())
+				oldNamespace := oldobj.(*corev1.Namespace)
+				newNamespace := newobj.(*corev1.Namespace)
+
+				// Only label changes can change which cluster-scoped configs select the namespace, and the opt-out
+				// annotation which pods are labelled at all
+				if !plc.HasSynced || (equality.Semantic.DeepEqual(oldNamespace.GetLabels(), newNamespace.GetLabels()) &&
+					oldNamespace.GetAnnotations()[exclusions.OptOutAnnotation] == newNamespace.GetAnnotations()[exclusions.OptOutAnnotation]) {
+					return
 				}
-			},
-			DeleteFunc: func(obj interface{}) {
-				log.Print("PodLabelConfig Delete Event")
-				// Do nothing
+
+				log.Printf("Namespace %s labels changed, queueing all pods", newNamespace.GetName())
+				plc.enqueuePods(newNamespace.GetName(), labels.Everything())
+				plc.enqueueWorkloads(newNamespace.GetName(), labels.Everything())
+				for _, w := range plc.resourceWatches.List() {
+					w.EnqueueAll(newNamespace.GetName())
+				}
+				plc.configs.EnqueueNamespace(newNamespace.GetName())
 			},
 		},
 	)
-
-	go plc.podLabelConfigController.Run(killChan)
 }
 
 func main() {
@@ -337,6 +1846,58 @@ func main() {
 	kubeconfig = flag.String("kubeconfig", filepath.Join(os.Getenv("HOME"), ".kube", "config"), "(optional) absolute path to the kubeconfig file")
 	var numPodWorkers *int
 	numPodWorkers = flag.Int("num-pod-workers", 1, "(optional) number of concurrent pod workers")
+	var dryRun *bool
+	dryRun = flag.Bool("dry-run", false, "(optional) only log and record events for the patches that would be made, never patch pods")
+	var metricsAddr *string
+	metricsAddr = flag.String("metrics-addr", ":8080", "(optional) address to serve prometheus metrics on. Disabled when empty")
+	var healthAddr *string
+	healthAddr = flag.String("health-addr", ":8081", "(optional) address to serve the /healthz and /readyz probes on. Disabled when empty")
+	var livenessTimeout *time.Duration
+	livenessTimeout = flag.Duration("liveness-timeout", 5*time.Minute, "(optional) how long workers may be stuck or the watch broken before /healthz fails")
+	var webhookAddr *string
+	webhookAddr = flag.String("webhook-addr", "", "(optional) address to serve the admission webhooks on, for example :8443. Disabled when empty")
+	var tlsCertFile *string
+	tlsCertFile = flag.String("tls-cert-file", "", "(optional) TLS certificate for the webhook server")
+	var tlsKeyFile *string
+	tlsKeyFile = flag.String("tls-key-file", "", "(optional) TLS private key for the webhook server")
+	var leaderElect *bool
+	leaderElect = flag.Bool("leader-elect", false, "(optional) only run the controller while holding the leader lock, so multiple replicas can run")
+	var leaderElectLockType *string
+	leaderElectLockType = flag.String("leader-elect-lock-type", resourcelock.ConfigMapsResourceLock, "(optional) type of object used as the leader lock, configmaps or endpoints")
+	var leaderElectNamespace *string
+	leaderElectNamespace = flag.String("leader-elect-namespace", "kube-system", "(optional) namespace of the leader lock")
+	var leaderElectName *string
+	leaderElectName = flag.String("leader-elect-name", "podlabeler", "(optional) name of the leader lock")
+	var leaseDuration *time.Duration
+	leaseDuration = flag.Duration("leader-elect-lease-duration", 15*time.Second, "(optional) how long standbys wait before taking over a lock that was not renewed")
+	var renewDeadline *time.Duration
+	renewDeadline = flag.Duration("leader-elect-renew-deadline", 10*time.Second, "(optional) how long the leader keeps trying to renew the lock before giving up")
+	var retryPeriod *time.Duration
+	retryPeriod = flag.Duration("leader-elect-retry-period", 2*time.Second, "(optional) how often to try to acquire or renew the lock")
+	var shutdownTimeout *time.Duration
+	shutdownTimeout = flag.Duration("shutdown-timeout", 30*time.Second, "(optional) how long to wait for in-flight work to finish on SIGINT or SIGTERM")
+	var excludeNamespaces *string
+	excludeNamespaces = flag.String("exclude-namespaces", "kube-system", "(optional) comma separated namespaces whose pods are never labelled")
+	var excludeOwnerKinds *string
+	excludeOwnerKinds = flag.String("exclude-owner-kinds", "", "(optional) comma separated kinds, such as DaemonSet, whose pods are never labelled")
+	var excludePodPhases *string
+	excludePodPhases = flag.String("exclude-pod-phases", "Succeeded,Failed", "(optional) comma separated pod phases that are never labelled")
+	var serverSideApplyEnabled *bool
+	serverSideApplyEnabled = flag.Bool("server-side-apply", false, "(optional) apply the labels and annotations of pods with server-side apply instead of strategic merge patches, needs Kubernetes 1.16+")
+	var fieldManager *string
+	fieldManager = flag.String("field-manager", "podlabeler", "(optional) field manager that owns the applied labels and annotations")
+	var forceConflicts *bool
+	forceConflicts = flag.Bool("force-conflicts", false, "(optional) take over labels and annotations owned by other field managers instead of reporting a conflict")
+	var kubeAPIQPS *float64
+	kubeAPIQPS = flag.Float64("kube-api-qps", 5, "(optional) queries per second sent to the apiserver by every client")
+	var kubeAPIBurst *int
+	kubeAPIBurst = flag.Int("kube-api-burst", 10, "(optional) queries the clients may send at once above kube-api-qps")
+	var writeQPS *float64
+	writeQPS = flag.Float64("write-qps", 0, "(optional) patches per second sent for pods, workloads and resources. Unlimited when 0")
+	var writeBurst *int
+	writeBurst = flag.Int("write-burst", 10, "(optional) patches that may be sent at once above write-qps")
+	var coalesceWindow *time.Duration
+	coalesceWindow = flag.Duration("coalesce-window", time.Second, "(optional) how long changes to a pod are collected before it is patched once for all of them")
 	flag.Parse()
 
 	// use the current context in kubeconfig
@@ -345,6 +1906,10 @@ func main() {
 		panic(err.Error())
 	}
 
+	// Every client built from the config shares these limits
+	config.QPS = float32(*kubeAPIQPS)
+	config.Burst = *kubeAPIBurst
+
 	// create the clientset
 	clientset, err := kubernetes.NewForConfig(config)
 	if err != nil {
@@ -357,9 +1922,68 @@ func main() {
 		panic(err.Error())
 	}
 
+	// ResourceLabelConfigs label arbitrary resources with the dynamic client
+	clientPool := dynamic.NewDynamicClientPool(config)
+
 	// Create controller, passing all clients
-	plc := NewPodLabelController(clientset, plClientset, numPodWorkers)
+	excluded := exclusions.New(*excludeNamespaces, *excludeOwnerKinds, *excludePodPhases)
+	var apply *serverSideApply
+	if *serverSideApplyEnabled {
+		apply = &serverSideApply{fieldManager: *fieldManager, force: *forceConflicts}
+	}
+	writeLimiter := flowcontrol.NewFakeAlwaysRateLimiter()
+	if *writeQPS > 0 {
+		writeLimiter = flowcontrol.NewTokenBucketRateLimiter(float32(*writeQPS), *writeBurst)
+	}
+	plc := NewPodLabelController(clientset, plClientset, clientPool, numPodWorkers, dryRun, excluded, apply, writeLimiter, *coalesceWindow)
+
+	if *metricsAddr != "" {
+		go plc.StartMetricsServer(*metricsAddr)
+	}
+
+	if *healthAddr != "" {
+		go plc.StartHealthServer(*healthAddr, *livenessTimeout)
+	}
+
+	// Serve the admission webhooks from the same process so they share the config stores
+	if *webhookAddr != "" {
+		go plc.StartWebhookServer(*webhookAddr, *tlsCertFile, *tlsKeyFile)
+	}
+
+	// Stop on SIGINT or SIGTERM, a second signal exits right away
+	stopCh := make(chan struct{})
+	signals := make(chan os.Signal, 2)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		sig := <-signals
+		log.Printf("Received %s, shutting down", sig)
+		close(stopCh)
+		<-signals
+		os.Exit(1)
+	}()
+
+	if !*leaderElect {
+		// Run controller
+		plc.Run(stopCh, *shutdownTimeout)
+		return
+	}
+
+	// The identity must be unique for every replica
+	hostname, err := os.Hostname()
+	if err != nil {
+		panic(err.Error())
+	}
+	identity := hostname + "_" + string(uuid.NewUUID())
+
+	lock, err := resourcelock.New(*leaderElectLockType, *leaderElectNamespace, *leaderElectName, clientset.CoreV1(),
+		resourcelock.ResourceLockConfig{
+			Identity:      identity,
+			EventRecorder: plc.recorder,
+		})
+	if err != nil {
+		panic(err.Error())
+	}
 
-	// Run controller
-	plc.Run()
+	// Run controller once elected
+	plc.RunWithLeaderElection(stopCh, *shutdownTimeout, lock, *leaseDuration, *renewDeadline, *retryPeriod)
 }
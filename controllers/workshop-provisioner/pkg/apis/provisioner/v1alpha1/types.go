@@ -9,6 +9,11 @@ import (
 // +genclient
 // +genclient:nonNamespaced
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:resource:scope=Cluster,shortName=wa;was
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Email",type=string,JSONPath=`.spec.email`
+// +kubebuilder:printcolumn:name="State",type=string,JSONPath=`.status.state`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
 
 // WorkshopAttendee represents an attendee that needs resource provisioned
 type WorkshopAttendee struct {
@@ -29,6 +34,8 @@ type WorkshopAttendeeSpec struct {
 	Email string `json:"email,omitempty"`
 }
 
+// WorkshopAttendeeState is one of Ready, Creating or Deleting
+// +kubebuilder:validation:Enum=Ready;Creating;Deleting
 type WorkshopAttendeeState string
 
 const (
@@ -8,30 +8,52 @@ import (
 	"flag"
 	"fmt"
 	logging "log"
+	"net/http"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"syscall"
 	"text/template"
 	"time"
 
+	// Prometheus
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
 	// Kubernetes and client-go
 	appsv1beta2 "k8s.io/api/apps/v1beta2"
 	corev1 "k8s.io/api/core/v1"
 	rbacv1beta1 "k8s.io/api/rbac/v1beta1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/fields"
 	machinery_runtime "k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/uuid"
 	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/informers"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	typedcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	appslisters "k8s.io/client-go/listers/apps/v1beta2"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	rbaclisters "k8s.io/client-go/listers/rbac/v1beta1"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/client-go/util/retry"
 	"k8s.io/client-go/util/workqueue"
 
 	// Custom resources
 	wpv1alpha1 "github.com/carsonoid/kube-crds-and-controllers/controllers/workshop-provisioner/pkg/apis/provisioner/v1alpha1"
 	wpclient "github.com/carsonoid/kube-crds-and-controllers/controllers/workshop-provisioner/pkg/client/clientset/versioned"
+	wpscheme "github.com/carsonoid/kube-crds-and-controllers/controllers/workshop-provisioner/pkg/client/clientset/versioned/scheme"
+	wpinformers "github.com/carsonoid/kube-crds-and-controllers/controllers/workshop-provisioner/pkg/client/informers/externalversions"
+	wplisters "github.com/carsonoid/kube-crds-and-controllers/controllers/workshop-provisioner/pkg/client/listers/provisioner/v1alpha1"
+	"github.com/carsonoid/kube-crds-and-controllers/pkg/election"
+	_ "github.com/carsonoid/kube-crds-and-controllers/pkg/metrics" // workqueue metrics
 )
 
 // BONUS: These values could be read dynamically from a configmap
@@ -62,56 +84,169 @@ users:
 
 var (
 	log = logging.New(os.Stdout, "", logging.Lshortfile)
+
+	droppedKeys = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: "workshop_provisioner",
+		Name:      "dropped_keys_total",
+		Help:      "Total number of attendee keys dropped from the queue after too many retries",
+	})
+	provisioningDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "workshop_provisioner",
+		Name:      "attendee_provisioning_duration_seconds",
+		Help:      "Time it took for an attendee to reach a state. Measured from creation for Creating and Ready, from the delete request for Deleting",
+		Buckets:   prometheus.ExponentialBuckets(0.5, 2, 10),
+	}, []string{"state"})
 )
 
+func init() {
+	prometheus.MustRegister(droppedKeys, provisioningDuration)
+}
+
 // WorkshopProvisionerController with a config and client
 type WorkshopProvisionerController struct {
 	client      *kubernetes.Clientset
 	wpClientset *wpclient.Clientset
+	recorder    record.EventRecorder
 
 	numAttendeeWorkers *int
 	ClusterAddr        *string
 
+	// All reads go through the shared informers of these factories, so every resource is watched once
+	kubeInformerFactory informers.SharedInformerFactory
+	wpInformerFactory   wpinformers.SharedInformerFactory
+
+	// The resources created for every attendee are checked against the cache
+	namespaceLister      corelisters.NamespaceLister
+	serviceAccountLister corelisters.ServiceAccountLister
+	roleBindingLister    rbaclisters.RoleBindingLister
+	deploymentLister     appslisters.DeploymentLister
+
+	attendeeLister   wplisters.WorkshopAttendeeLister
 	attendeeIndexer  cache.Indexer
 	attendeeQueue    workqueue.RateLimitingInterface
-	attendeeInformer cache.Controller
+	attendeeInformer cache.SharedIndexInformer
+
+	// workers tracks the running queue workers so shutdown can wait for in-flight items
+	workers sync.WaitGroup
+
+	// Health tracking, all values are accessed atomically
+	// attendeesSynced is 1 once the attendee informer has synced and the workers are started
+	attendeesSynced int32
+	// lastAttendeeProcessed is the unix time the last attendee was processed, used to detect wedged workers
+	lastAttendeeProcessed int64
+	// lastAttendeeEvent is the unix time of the last attendee event or resync, used to detect a broken watch
+	lastAttendeeEvent int64
 }
 
 // NewWorkshopProvisionerController takes a kubernetes clientset and configuration and returns a valid WorkshopProvisionerController
 func NewWorkshopProvisionerController(client *kubernetes.Clientset, wpClientset *wpclient.Clientset, numAttendeeWorkers *int, ca *string) *WorkshopProvisionerController {
+	// Events are recorded on the attendees for every resource created for them.
+	// The custom types must be known to the scheme to reference them from events
+	wpscheme.AddToScheme(scheme.Scheme)
+	eventBroadcaster := record.NewBroadcaster()
+	eventBroadcaster.StartRecordingToSink(&typedcorev1.EventSinkImpl{Interface: client.CoreV1().Events("")})
+
+	// Attendees resync every 30 seconds so deleted resources are recreated
+	kubeInformerFactory := informers.NewSharedInformerFactory(client, 0)
+	wpInformerFactory := wpinformers.NewSharedInformerFactory(wpClientset, time.Second*30)
+
+	attendees := wpInformerFactory.Provisioner().V1alpha1().WorkshopAttendees()
 	return &WorkshopProvisionerController{
-		client:             client,
-		wpClientset:        wpClientset,
-		numAttendeeWorkers: numAttendeeWorkers,
-		ClusterAddr:        ca,
+		client:               client,
+		wpClientset:          wpClientset,
+		recorder:             eventBroadcaster.NewRecorder(scheme.Scheme, corev1.EventSource{Component: "workshop-provisioner"}),
+		numAttendeeWorkers:   numAttendeeWorkers,
+		ClusterAddr:          ca,
+		kubeInformerFactory:  kubeInformerFactory,
+		wpInformerFactory:    wpInformerFactory,
+		namespaceLister:      kubeInformerFactory.Core().V1().Namespaces().Lister(),
+		serviceAccountLister: kubeInformerFactory.Core().V1().ServiceAccounts().Lister(),
+		roleBindingLister:    kubeInformerFactory.Rbac().V1beta1().RoleBindings().Lister(),
+		deploymentLister:     kubeInformerFactory.Apps().V1beta2().Deployments().Lister(),
+		attendeeLister:       attendees.Lister(),
+		attendeeIndexer:      attendees.Informer().GetIndexer(),
+		attendeeInformer:     attendees.Informer(),
 	}
 }
 
-// Run starts the WorkshopProvisionerController and blocks until killed
-func (wpc *WorkshopProvisionerController) Run() {
+// RunWithLeaderElection only runs the WorkshopProvisionerController while this replica holds the lock.
+// Standby replicas block until they are elected. Losing the lock exits the process so no work is done twice.
+// Standbys return as soon as stopCh is closed, the leader returns once Run has drained its queue and the lock
+// is released.
+func (wpc *WorkshopProvisionerController) RunWithLeaderElection(stopCh <-chan struct{}, drainTimeout time.Duration, lock resourcelock.Interface, leaseDuration, renewDeadline, retryPeriod time.Duration) {
+	election.Run(stopCh, lock, leaseDuration, renewDeadline, retryPeriod, func(stopCh <-chan struct{}) {
+		wpc.Run(stopCh, drainTimeout)
+	})
+}
+
+// Run starts the WorkshopProvisionerController and blocks until stopCh is closed.
+// On shutdown the attendee queue is drained for up to drainTimeout so no provisioning step is interrupted.
+func (wpc *WorkshopProvisionerController) Run(stopCh <-chan struct{}, drainTimeout time.Duration) {
 	killChan := make(chan struct{})
-	defer close(killChan)
+	go func() {
+		<-stopCh
+		close(killChan)
+	}()
 
 	// Start crd controller
-	go wpc.StartAttendeeController(killChan)
+	wpc.StartAttendeeController(killChan)
+
+	// Every informer requested from the factories is started once all handlers are registered
+	wpc.kubeInformerFactory.Start(killChan)
+	wpc.wpInformerFactory.Start(killChan)
+
+	// Wait for all involved caches to be synced, before processing items from the queue is started
+	synced := wpc.kubeInformerFactory.WaitForCacheSync(killChan)
+	for informerType, ok := range wpc.wpInformerFactory.WaitForCacheSync(killChan) {
+		synced[informerType] = ok
+	}
+	for informerType, ok := range synced {
+		if !ok {
+			runtime.HandleError(fmt.Errorf("Timed out waiting for %v caches to sync", informerType))
+			return
+		}
+	}
+
+	go wpc.StartQueueWorkers(*wpc.numAttendeeWorkers, killChan)
 
 	// BONUS: The various sub-resources could be watched for changes/deletes directly. And reconciles could be immediately triggered.
 
 	<-killChan
+
+	wpc.drainWorkers(drainTimeout)
+}
+
+// drainWorkers waits for the queue workers to finish the items they are processing, for up to timeout
+func (wpc *WorkshopProvisionerController) drainWorkers(timeout time.Duration) {
+	log.Printf("Waiting up to %s for in-flight attendees to finish", timeout)
+
+	drained := make(chan struct{})
+	go func() {
+		wpc.workers.Wait()
+		close(drained)
+	}()
+
+	select {
+	case <-drained:
+		log.Print("All attendee workers stopped")
+	case <-time.After(timeout):
+		log.Printf("Timed out waiting for attendee workers, %d attendees left in the queue", wpc.attendeeQueue.Len())
+	}
 }
 
+// StartAttendeeController creates the attendee queue and registers the attendee event handlers on the shared informer.
+// The informer itself is started by Run.
 func (wpc *WorkshopProvisionerController) StartAttendeeController(killChan chan struct{}) {
 	log.Println("Starting WorkshopAttendee controller")
 
-	restClient := wpc.wpClientset.ProvisionerV1alpha1().RESTClient()
-	listwatch := cache.NewListWatchFromClient(restClient, "workshopattendees", corev1.NamespaceAll, fields.Everything())
+	// The queue is named so its metrics can be told apart
+	wpc.attendeeQueue = workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "attendeeQueue")
 
-	wpc.attendeeQueue = workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter())
-
-	wpc.attendeeIndexer, wpc.attendeeInformer = cache.NewIndexerInformer(listwatch, &wpv1alpha1.WorkshopAttendee{}, time.Second*30,
+	wpc.attendeeInformer.AddEventHandler(
 		cache.ResourceEventHandlerFuncs{
 			AddFunc: func(obj interface{}) {
 				// log.Print("WorkshopAttendee Add Event")
+				atomic.StoreInt64(&wpc.lastAttendeeEvent, time.Now().Unix())
 				key, err := cache.MetaNamespaceKeyFunc(obj)
 				if err == nil {
 					wpc.attendeeQueue.Add(key)
@@ -119,6 +254,7 @@ func (wpc *WorkshopProvisionerController) StartAttendeeController(killChan chan
 			},
 			UpdateFunc: func(oldobj interface{}, newobj interface{}) {
 				// log.Print("WorkshopAttendee Update Event")
+				atomic.StoreInt64(&wpc.lastAttendeeEvent, time.Now().Unix())
 				key, err := cache.MetaNamespaceKeyFunc(newobj)
 				if err == nil {
 					wpc.attendeeQueue.Add(key)
@@ -132,9 +268,7 @@ func (wpc *WorkshopProvisionerController) StartAttendeeController(killChan chan
 					wpc.attendeeQueue.Add(key)
 				}
 			},
-		}, cache.Indexers{})
-
-	go wpc.StartQueueWorkers(*wpc.numAttendeeWorkers, killChan)
+		})
 }
 
 func (wpc *WorkshopProvisionerController) StartQueueWorkers(threadiness int, stopCh chan struct{}) {
@@ -144,17 +278,17 @@ func (wpc *WorkshopProvisionerController) StartQueueWorkers(threadiness int, sto
 	defer wpc.attendeeQueue.ShutDown()
 	log.Println("Starting Attendee Queue Workers")
 
-	go wpc.attendeeInformer.Run(stopCh)
-
-	// Wait for all involved caches to be synced, before processing items from the queue is started
-	if !cache.WaitForCacheSync(stopCh, wpc.attendeeInformer.HasSynced) {
-		runtime.HandleError(fmt.Errorf("Timed out waiting for caches to sync"))
-		return
-	}
-
+	// The caches are already synced by Run
+	atomic.StoreInt64(&wpc.lastAttendeeProcessed, time.Now().Unix())
 	for i := 0; i < threadiness; i++ {
-		go wait.Until(wpc.runQueueWorker, time.Second, stopCh)
+		wpc.workers.Add(1)
+		go func() {
+			defer wpc.workers.Done()
+			// Once the queue is shut down the worker finishes its current item and returns
+			wait.Until(wpc.runQueueWorker, time.Second, stopCh)
+		}()
 	}
+	atomic.StoreInt32(&wpc.attendeesSynced, 1)
 
 	<-stopCh
 	log.Println("Stopping Attendee Queue Workers")
@@ -171,6 +305,11 @@ func (wpc *WorkshopProvisionerController) processNextPod() bool {
 	if quit {
 		return false
 	}
+	// Stop taking new items on shutdown, they are listed again by the informer on the next start
+	if wpc.attendeeQueue.ShuttingDown() {
+		wpc.attendeeQueue.Done(key)
+		return false
+	}
 	// Tell the queue that we are done with processing this key. This unblocks the key for other workers
 	// This allows safe parallel processing because two resources with the same key are never processed in
 	// parallel.
@@ -180,6 +319,7 @@ func (wpc *WorkshopProvisionerController) processNextPod() bool {
 	err := wpc.processAttendee(key.(string))
 	// Handle the error if something went wrong during the execution of the business logic
 	wpc.handleErr(err, key)
+	atomic.StoreInt64(&wpc.lastAttendeeProcessed, time.Now().Unix())
 	return true
 }
 
@@ -190,11 +330,10 @@ func (wpc *WorkshopProvisionerController) processAttendee(key string) error {
 		return err
 	}
 
-	if exists {
-		wpc.reconcileAttendee(obj.(*wpv1alpha1.WorkshopAttendee))
+	if !exists {
+		return nil
 	}
-
-	return nil
+	return wpc.reconcileAttendee(obj.(*wpv1alpha1.WorkshopAttendee))
 }
 
 // handleErr checks if an error happened and makes sure we will retry later.
@@ -220,6 +359,7 @@ func (wpc *WorkshopProvisionerController) handleErr(err error, key interface{})
 	wpc.attendeeQueue.Forget(key)
 	// Report to an external entity that, even after several retries, we could not successfully process this key
 	runtime.HandleError(err)
+	droppedKeys.Inc()
 	log.Printf("Dropping pod %q out of the queue: %v\n", key, err)
 }
 
@@ -233,15 +373,17 @@ func (wpc *WorkshopProvisionerController) GetNamespaceName(wa *wpv1alpha1.Worksh
 }
 
 func (wpc *WorkshopProvisionerController) GetServiceAccountToken(wa *wpv1alpha1.WorkshopAttendee) (string, error) {
-	saClient := wpc.client.CoreV1().ServiceAccounts(wpc.GetNamespaceName(wa))
-
 	// Get the
-	sa, saErr := saClient.Get(AttendeeServiceAccountName, metav1.GetOptions{})
+	sa, saErr := wpc.serviceAccountLister.ServiceAccounts(wpc.GetNamespaceName(wa)).Get(AttendeeServiceAccountName)
 	if saErr != nil {
 		log.Printf("Error getting service account token for %s", wa.GetName())
 		return "", saErr
 	}
+	if len(sa.Secrets) == 0 {
+		return "", fmt.Errorf("no token generated yet for service account of %s", wa.GetName())
+	}
 
+	// Secrets are read from the apiserver so the tokens of every namespace are not kept in memory
 	secretClient := wpc.client.CoreV1().Secrets(wpc.GetNamespaceName(wa))
 
 	// Create the namespace if it doesn't exist
@@ -279,12 +421,15 @@ func (wpc *WorkshopProvisionerController) UpdateChildStatus(wa *wpv1alpha1.Works
 
 	provisionerClient := wpc.wpClientset.ProvisionerV1alpha1().WorkshopAttendees()
 
+	live := false
 	retryErr := retry.RetryOnConflict(retry.DefaultRetry, func() error {
 		// Retrieve the latest version of the WorkshopAttendee before attempting update
 		// RetryOnConflict uses exponential backoff to avoid exhausting the apiserver
-		result, getErr := provisionerClient.Get(wa.GetName(), metav1.GetOptions{})
+		result, getErr := wpc.getAttendee(wa, live)
+		live = true
 		if getErr != nil {
 			log.Printf("Failed to get latest version of WorkshopAttendee: %v", getErr)
+			return getErr
 		}
 
 		// initialize empty map if needed
@@ -297,7 +442,7 @@ func (wpc *WorkshopProvisionerController) UpdateChildStatus(wa *wpv1alpha1.Works
 		// If we are setting a child status, We are assumed to be in a creating state again.
 		result.Status.State = wpv1alpha1.WorkshopAttendeeStateCreating
 
-		_, updateErr := provisionerClient.Update(result)
+		_, updateErr := provisionerClient.UpdateStatus(result)
 		return updateErr
 	})
 	if retryErr != nil {
@@ -311,12 +456,15 @@ func (wpc *WorkshopProvisionerController) UpdateFinalState(wa *wpv1alpha1.Worksh
 
 	provisionerClient := wpc.wpClientset.ProvisionerV1alpha1().WorkshopAttendees()
 
+	live := false
 	retryErr := retry.RetryOnConflict(retry.DefaultRetry, func() error {
 		// Retrieve the latest version of the WorkshopAttendee before attempting update
 		// RetryOnConflict uses exponential backoff to avoid exhausting the apiserver
-		result, getErr := provisionerClient.Get(wa.GetName(), metav1.GetOptions{})
+		result, getErr := wpc.getAttendee(wa, live)
+		live = true
 		if getErr != nil {
 			log.Printf("Failed to get latest version of WorkshopAttendee: %v", getErr)
+			return getErr
 		}
 
 		// Set ready state
@@ -325,7 +473,7 @@ func (wpc *WorkshopProvisionerController) UpdateFinalState(wa *wpv1alpha1.Worksh
 		// set kubeconfig
 		result.Status.Kubeconfig = wpc.GetKubeconfig(wa)
 
-		_, updateErr := provisionerClient.Update(result)
+		_, updateErr := provisionerClient.UpdateStatus(result)
 		return updateErr
 	})
 	if retryErr != nil {
@@ -339,17 +487,20 @@ func (wpc *WorkshopProvisionerController) UpdateState(wa *wpv1alpha1.WorkshopAtt
 
 	provisionerClient := wpc.wpClientset.ProvisionerV1alpha1().WorkshopAttendees()
 
+	live := false
 	retryErr := retry.RetryOnConflict(retry.DefaultRetry, func() error {
 		// Retrieve the latest version of the WorkshopAttendee before attempting update
 		// RetryOnConflict uses exponential backoff to avoid exhausting the apiserver
-		result, getErr := provisionerClient.Get(wa.GetName(), metav1.GetOptions{})
+		result, getErr := wpc.getAttendee(wa, live)
+		live = true
 		if getErr != nil {
 			log.Printf("Failed to get latest version of WorkshopAttendee: %v", getErr)
+			return getErr
 		}
 
 		result.Status.State = s
 
-		_, updateErr := provisionerClient.Update(result)
+		_, updateErr := provisionerClient.UpdateStatus(result)
 		return updateErr
 	})
 	if retryErr != nil {
@@ -358,6 +509,20 @@ func (wpc *WorkshopProvisionerController) UpdateState(wa *wpv1alpha1.WorkshopAtt
 	return nil
 }
 
+// getAttendee returns a copy of a WorkshopAttendee to update. The cache is read unless live is set,
+// retries after a conflict set it because the cache may not have seen the latest version yet.
+func (wpc *WorkshopProvisionerController) getAttendee(wa *wpv1alpha1.WorkshopAttendee, live bool) (*wpv1alpha1.WorkshopAttendee, error) {
+	if live {
+		return wpc.wpClientset.ProvisionerV1alpha1().WorkshopAttendees().Get(wa.GetName(), metav1.GetOptions{})
+	}
+
+	cached, err := wpc.attendeeLister.Get(wa.GetName())
+	if err != nil {
+		return nil, err
+	}
+	return cached.DeepCopy(), nil
+}
+
 func filter(vs []string, f func(string) bool) []string {
 	var vsf []string
 	for _, v := range vs {
@@ -372,10 +537,12 @@ func (wpc *WorkshopProvisionerController) removeFinalizer(wa *wpv1alpha1.Worksho
 	provisionerClient := wpc.wpClientset.ProvisionerV1alpha1().WorkshopAttendees()
 
 	// Finalizer not already set. Add it
+	live := false
 	retryErr := retry.RetryOnConflict(retry.DefaultRetry, func() error {
 		// Retrieve the latest version before attempting update
 		// RetryOnConflict uses exponential backoff to avoid exhausting the apiserver
-		result, getErr := provisionerClient.Get(wa.GetName(), metav1.GetOptions{})
+		result, getErr := wpc.getAttendee(wa, live)
+		live = true
 		if getErr != nil {
 			return getErr
 		}
@@ -416,10 +583,12 @@ func (wpc *WorkshopProvisionerController) reconcileFinalizer(wa *wpv1alpha1.Work
 	}
 
 	// Finalizer not already set. Add it
+	live := false
 	retryErr := retry.RetryOnConflict(retry.DefaultRetry, func() error {
 		// Retrieve the latest version before attempting update
 		// RetryOnConflict uses exponential backoff to avoid exhausting the apiserver
-		result, getErr := provisionerClient.Get(wa.GetName(), metav1.GetOptions{})
+		result, getErr := wpc.getAttendee(wa, live)
+		live = true
 		if getErr != nil {
 			return getErr
 		}
@@ -445,7 +614,7 @@ func (wpc *WorkshopProvisionerController) reconcileNamespace(wa *wpv1alpha1.Work
 	nsClient := wpc.client.CoreV1().Namespaces()
 
 	// Create the namespace if it doesn't exist
-	if _, err := nsClient.Get(nsName, metav1.GetOptions{}); err != nil {
+	if _, err := wpc.namespaceLister.Get(nsName); errors.IsNotFound(err) {
 		log.Printf("Creating Namespace for %s", wa.GetName())
 		ns := &corev1.Namespace{
 			ObjectMeta: metav1.ObjectMeta{
@@ -454,11 +623,17 @@ func (wpc *WorkshopProvisionerController) reconcileNamespace(wa *wpv1alpha1.Work
 		}
 
 		_, err := nsClient.Create(ns)
+		// The cache may not have seen a resource created by an earlier reconcile yet
+		if errors.IsAlreadyExists(err) {
+			return nil
+		}
 		if err != nil {
 			log.Printf("Error creating namespace: %s", err)
+			wpc.recorder.Eventf(wa, corev1.EventTypeWarning, "CreateFailed", "Error creating Namespace %s: %s", nsName, err)
 			return err
 		}
 
+		wpc.recorder.Eventf(wa, corev1.EventTypeNormal, "Created", "Created Namespace %s", nsName)
 		wpc.UpdateChildStatus(wa, "namespace")
 	}
 
@@ -469,7 +644,7 @@ func (wpc *WorkshopProvisionerController) reconcileServiceAccount(wa *wpv1alpha1
 	saClient := wpc.client.CoreV1().ServiceAccounts(wpc.GetNamespaceName(wa))
 
 	// Create the namespace if it doesn't exist
-	if _, err := saClient.Get(AttendeeServiceAccountName, metav1.GetOptions{}); err != nil {
+	if _, err := wpc.serviceAccountLister.ServiceAccounts(wpc.GetNamespaceName(wa)).Get(AttendeeServiceAccountName); errors.IsNotFound(err) {
 		log.Printf("Creating ServiceAccount for %s", wa.GetName())
 		ns := &corev1.ServiceAccount{
 			ObjectMeta: metav1.ObjectMeta{
@@ -478,11 +653,16 @@ func (wpc *WorkshopProvisionerController) reconcileServiceAccount(wa *wpv1alpha1
 		}
 
 		_, err := saClient.Create(ns)
+		if errors.IsAlreadyExists(err) {
+			return nil
+		}
 		if err != nil {
 			log.Printf("Error creating namespace: %s", err)
+			wpc.recorder.Eventf(wa, corev1.EventTypeWarning, "CreateFailed", "Error creating ServiceAccount %s: %s", AttendeeServiceAccountName, err)
 			return err
 		}
 
+		wpc.recorder.Eventf(wa, corev1.EventTypeNormal, "Created", "Created ServiceAccount %s", AttendeeServiceAccountName)
 		wpc.UpdateChildStatus(wa, "serviceaccount")
 	}
 
@@ -494,7 +674,7 @@ func (wpc *WorkshopProvisionerController) reconcileRoleBinding(wa *wpv1alpha1.Wo
 	rbClient := wpc.client.RbacV1beta1().RoleBindings(nsName)
 
 	// Create the namespace if it doesn't exist
-	if _, err := rbClient.Get(AttendeeServiceAccountName, metav1.GetOptions{}); err != nil {
+	if _, err := wpc.roleBindingLister.RoleBindings(nsName).Get(AttendeeServiceAccountName); errors.IsNotFound(err) {
 		log.Printf("Creating RoleBinding for %s", wa.GetName())
 		ns := &rbacv1beta1.RoleBinding{
 			ObjectMeta: metav1.ObjectMeta{
@@ -516,11 +696,16 @@ func (wpc *WorkshopProvisionerController) reconcileRoleBinding(wa *wpv1alpha1.Wo
 		}
 
 		_, err := rbClient.Create(ns)
+		if errors.IsAlreadyExists(err) {
+			return nil
+		}
 		if err != nil {
 			log.Printf("Error creating namespace: %s", err)
+			wpc.recorder.Eventf(wa, corev1.EventTypeWarning, "CreateFailed", "Error creating RoleBinding %s: %s", AttendeeServiceAccountName, err)
 			return err
 		}
 
+		wpc.recorder.Eventf(wa, corev1.EventTypeNormal, "Created", "Created RoleBinding %s", AttendeeServiceAccountName)
 		wpc.UpdateChildStatus(wa, "rolebinding")
 	}
 
@@ -537,7 +722,7 @@ func (wpc *WorkshopProvisionerController) reconcileDeployments(wa *wpv1alpha1.Wo
 
 	// Create the deployments if they don't exist
 	for _, app := range apps {
-		if _, err := depClient.Get(app, metav1.GetOptions{}); err != nil {
+		if _, err := wpc.deploymentLister.Deployments(nsName).Get(app); errors.IsNotFound(err) {
 			log.Printf("Creating Deployment %s for %s", app, wa.GetName())
 			deployment := &appsv1beta2.Deployment{
 				ObjectMeta: metav1.ObjectMeta{
@@ -576,11 +761,17 @@ func (wpc *WorkshopProvisionerController) reconcileDeployments(wa *wpv1alpha1.Wo
 			}
 
 			_, err := depClient.Create(deployment)
+			// Created by an earlier reconcile but not in the cache yet
+			if errors.IsAlreadyExists(err) {
+				continue
+			}
 			if err != nil {
 				log.Printf("Error creating deployment: %s", err)
+				wpc.recorder.Eventf(wa, corev1.EventTypeWarning, "CreateFailed", "Error creating Deployment %s: %s", app, err)
 				return err
 			}
 
+			wpc.recorder.Eventf(wa, corev1.EventTypeNormal, "Created", "Created Deployment %s", app)
 			wpc.UpdateChildStatus(wa, "deployment:"+app)
 		}
 	}
@@ -595,6 +786,8 @@ func (wpc *WorkshopProvisionerController) reportOnReady(wa *wpv1alpha1.WorkshopA
 
 		// Update status/kubeconfig
 		wpc.UpdateFinalState(wa)
+		wpc.recorder.Event(wa, corev1.EventTypeNormal, "Ready", "Provisioning is complete")
+		observeProvisioningDuration(wpv1alpha1.WorkshopAttendeeStateReady, wa.GetCreationTimestamp())
 
 		// Send result email
 		log.Printf("Sent email to %s", wa.Spec.Email)
@@ -603,42 +796,113 @@ func (wpc *WorkshopProvisionerController) reportOnReady(wa *wpv1alpha1.WorkshopA
 	return nil
 }
 
+// observeProvisioningDuration records how long it took an attendee to reach a state since the given time
+func observeProvisioningDuration(state wpv1alpha1.WorkshopAttendeeState, since metav1.Time) {
+	provisioningDuration.WithLabelValues(string(state)).Observe(time.Since(since.Time).Seconds())
+}
+
+// StartMetricsServer serves the prometheus metrics on /metrics until the server fails
+func (wpc *WorkshopProvisionerController) StartMetricsServer(addr string) {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+
+	log.Printf("Starting metrics server on %s", addr)
+	if err := http.ListenAndServe(addr, mux); err != nil {
+		log.Fatalf("Metrics server failed: %s", err)
+	}
+}
+
+// StartHealthServer serves the /healthz liveness and /readyz readiness probes until the server fails.
+// The controller is considered dead when no attendee was processed for livenessTimeout while attendees are queued,
+// or when no attendee event was seen for livenessTimeout while attendees exist.
+func (wpc *WorkshopProvisionerController) StartHealthServer(addr string, livenessTimeout time.Duration) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		if err := wpc.checkLiveness(livenessTimeout); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		fmt.Fprint(w, "ok")
+	})
+	mux.HandleFunc("/readyz", func(w http.ResponseWriter, r *http.Request) {
+		if atomic.LoadInt32(&wpc.attendeesSynced) == 0 {
+			http.Error(w, "caches are not synced", http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprint(w, "ok")
+	})
+
+	log.Printf("Starting health server on %s", addr)
+	if err := http.ListenAndServe(addr, mux); err != nil {
+		log.Fatalf("Health server failed: %s", err)
+	}
+}
+
+// checkLiveness returns an error if the attendee workers are wedged or the attendee watch is broken.
+// Nothing is checked before the cache is synced, that is covered by the readiness probe.
+func (wpc *WorkshopProvisionerController) checkLiveness(timeout time.Duration) error {
+	if atomic.LoadInt32(&wpc.attendeesSynced) == 0 {
+		return nil
+	}
+
+	lastProcessed := time.Unix(atomic.LoadInt64(&wpc.lastAttendeeProcessed), 0)
+	if wpc.attendeeQueue.Len() > 0 && time.Since(lastProcessed) > timeout {
+		return fmt.Errorf("no attendee processed since %s with %d attendees queued", lastProcessed, wpc.attendeeQueue.Len())
+	}
+
+	// Resyncs only happen while attendees exist, an empty store says nothing about the watch
+	lastEvent := time.Unix(atomic.LoadInt64(&wpc.lastAttendeeEvent), 0)
+	if len(wpc.attendeeIndexer.ListKeys()) > 0 && time.Since(lastEvent) > timeout {
+		return fmt.Errorf("no attendee event or resync since %s, the watch is broken", lastEvent)
+	}
+	return nil
+}
+
+// deleteAttendeeResources deletes the namespace of an attendee and removes the finalizer once the namespace is gone.
+// The namespace controller does the teardown, until then the attendee is queued again instead of holding a worker.
 func (wpc *WorkshopProvisionerController) deleteAttendeeResources(wa *wpv1alpha1.WorkshopAttendee) error {
-	// Check/Delete Namespace. Let the namespace controller do the hard work
 	nsName := wpc.GetNamespaceName(wa)
 	nsClient := wpc.client.CoreV1().Namespaces()
 
-	// Deleting the namespace will trigger it's teardown process. We keep checking/deleting until the resource is gone
 	log.Printf("Deleting all resources for %s", wa.GetName())
-	for {
-		// Delete the namespace it exists and is not already set to be deleted
-		r, err := nsClient.Get(nsName, metav1.GetOptions{})
 
-		// namespace already deleted
-		if errors.IsNotFound(err) {
-			log.Printf("Namespace for %s is deleted", wa.GetName())
-			break
-		}
-
-		// NS exists and is not already set for deletion
-		if err == nil && r.GetDeletionTimestamp() == nil {
-			err := nsClient.Delete(nsName, &metav1.DeleteOptions{})
-
-			if err != nil {
-				log.Printf("Error deleting namespace: %s", err)
-				return err
-			}
+	// Delete the namespace if it exists and is not already set to be deleted
+	r, err := wpc.namespaceLister.Get(nsName)
+	if err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	deleted := errors.IsNotFound(err)
 
+	if !deleted && r.GetDeletionTimestamp() == nil {
+		err := nsClient.Delete(nsName, &metav1.DeleteOptions{})
+		// The cache can lag behind, a namespace that is already gone needs no deleting
+		if errors.IsNotFound(err) {
+			deleted = true
+		} else if err != nil {
+			log.Printf("Error deleting namespace: %s", err)
+			wpc.recorder.Eventf(wa, corev1.EventTypeWarning, "DeleteFailed", "Error deleting Namespace %s: %s", nsName, err)
+			return err
+		} else {
+			wpc.recorder.Eventf(wa, corev1.EventTypeNormal, "Deleting", "Deleting Namespace %s", nsName)
 			wpc.UpdateState(wa, wpv1alpha1.WorkshopAttendeeStateDeleting)
 		}
+	}
 
+	if !deleted {
 		log.Printf("Waiting for %s delete", wa.GetName())
-		time.Sleep(time.Second * 3)
+		key, err := cache.MetaNamespaceKeyFunc(wa)
+		if err != nil {
+			return err
+		}
+		wpc.attendeeQueue.AddAfter(key, time.Second*3)
+		return nil
 	}
 
 	log.Printf("Resources for attendee %s deleted", wa.GetName())
-	wpc.removeFinalizer(wa)
-	return nil
+	if t := wa.GetDeletionTimestamp(); t != nil {
+		observeProvisioningDuration(wpv1alpha1.WorkshopAttendeeStateDeleting, *t)
+	}
+	return wpc.removeFinalizer(wa)
 }
 
 func (wpc *WorkshopProvisionerController) reconcileAttendee(in *wpv1alpha1.WorkshopAttendee) error {
@@ -662,6 +926,7 @@ func (wpc *WorkshopProvisionerController) reconcileAttendee(in *wpv1alpha1.Works
 
 		// update status
 		wpc.UpdateState(wa, wpv1alpha1.WorkshopAttendeeStateCreating)
+		observeProvisioningDuration(wpv1alpha1.WorkshopAttendeeStateCreating, wa.GetCreationTimestamp())
 	}
 
 	// BONUS: Mostof these actions are very similar. There could be some cleanup to remove repitition
@@ -697,6 +962,28 @@ func main() {
 
 	var numAttendeeWorkers *int
 	numAttendeeWorkers = flag.Int("num-attendee-workers", 5, "(optional) number of concurrent attendee workers")
+	var metricsAddr *string
+	metricsAddr = flag.String("metrics-addr", ":8080", "(optional) address to serve prometheus metrics on. Disabled when empty")
+	var leaderElect *bool
+	leaderElect = flag.Bool("leader-elect", false, "(optional) only run the controller while holding the leader lock, so multiple replicas can run")
+	var leaderElectLockType *string
+	leaderElectLockType = flag.String("leader-elect-lock-type", resourcelock.ConfigMapsResourceLock, "(optional) type of object used as the leader lock, configmaps or endpoints")
+	var leaderElectNamespace *string
+	leaderElectNamespace = flag.String("leader-elect-namespace", "kube-system", "(optional) namespace of the leader lock")
+	var leaderElectName *string
+	leaderElectName = flag.String("leader-elect-name", "workshop-provisioner", "(optional) name of the leader lock")
+	var leaseDuration *time.Duration
+	leaseDuration = flag.Duration("leader-elect-lease-duration", 15*time.Second, "(optional) how long standbys wait before taking over a lock that was not renewed")
+	var renewDeadline *time.Duration
+	renewDeadline = flag.Duration("leader-elect-renew-deadline", 10*time.Second, "(optional) how long the leader keeps trying to renew the lock before giving up")
+	var retryPeriod *time.Duration
+	retryPeriod = flag.Duration("leader-elect-retry-period", 2*time.Second, "(optional) how often to try to acquire or renew the lock")
+	var healthAddr *string
+	healthAddr = flag.String("health-addr", ":8081", "(optional) address to serve the /healthz and /readyz probes on. Disabled when empty")
+	var livenessTimeout *time.Duration
+	livenessTimeout = flag.Duration("liveness-timeout", 5*time.Minute, "(optional) how long workers may be stuck or the watch broken before /healthz fails")
+	var shutdownTimeout *time.Duration
+	shutdownTimeout = flag.Duration("shutdown-timeout", 30*time.Second, "(optional) how long to wait for in-flight work to finish on SIGINT or SIGTERM")
 	flag.Parse()
 
 	// use the current context in kubeconfig
@@ -722,6 +1009,48 @@ func main() {
 	// Create controller, passing all clients
 	wpc := NewWorkshopProvisionerController(clientset, wpClientset, numAttendeeWorkers, clusterAddr)
 
-	// Run controller
-	wpc.Run()
+	if *metricsAddr != "" {
+		go wpc.StartMetricsServer(*metricsAddr)
+	}
+
+	if *healthAddr != "" {
+		go wpc.StartHealthServer(*healthAddr, *livenessTimeout)
+	}
+
+	// Stop on SIGINT or SIGTERM, a second signal exits right away
+	stopCh := make(chan struct{})
+	signals := make(chan os.Signal, 2)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		sig := <-signals
+		log.Printf("Received %s, shutting down", sig)
+		close(stopCh)
+		<-signals
+		os.Exit(1)
+	}()
+
+	if !*leaderElect {
+		// Run controller
+		wpc.Run(stopCh, *shutdownTimeout)
+		return
+	}
+
+	// The identity must be unique for every replica
+	hostname, err := os.Hostname()
+	if err != nil {
+		panic(err.Error())
+	}
+	identity := hostname + "_" + string(uuid.NewUUID())
+
+	lock, err := resourcelock.New(*leaderElectLockType, *leaderElectNamespace, *leaderElectName, clientset.CoreV1(),
+		resourcelock.ResourceLockConfig{
+			Identity:      identity,
+			EventRecorder: wpc.recorder,
+		})
+	if err != nil {
+		panic(err.Error())
+	}
+
+	// Run controller once elected
+	wpc.RunWithLeaderElection(stopCh, *shutdownTimeout, lock, *leaseDuration, *renewDeadline, *retryPeriod)
 }
@@ -0,0 +1,122 @@
+// Package election runs a controller only while its replica holds the leader lock.
+//
+// The vendored client-go elector cannot be stopped. The lock it renews is wrapped so it can be released on
+// shutdown, after which every further update fails and the elector gives up the lease.
+package election
+
+import (
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/leaderelection"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+)
+
+// releasableLock is a leader election lock that can be given up on shutdown
+type releasableLock struct {
+	resourcelock.Interface
+
+	lock     sync.Mutex
+	released bool
+}
+
+func (l *releasableLock) Create(record resourcelock.LeaderElectionRecord) error {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	if l.released {
+		return fmt.Errorf("lock %s was released", l.Describe())
+	}
+	return l.Interface.Create(record)
+}
+
+func (l *releasableLock) Update(record resourcelock.LeaderElectionRecord) error {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	if l.released {
+		return fmt.Errorf("lock %s was released", l.Describe())
+	}
+	return l.Interface.Update(record)
+}
+
+// release clears the holder of the lock if this replica still holds it, like newer elector versions do on
+// shutdown. Standbys of this version still wait for the lease they last saw to expire.
+func (l *releasableLock) release() error {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.released = true
+
+	record, err := l.Interface.Get()
+	if err != nil {
+		return err
+	}
+	if record.HolderIdentity != l.Identity() {
+		return nil
+	}
+
+	record.HolderIdentity = ""
+	record.LeaseDurationSeconds = 1
+	record.RenewTime = metav1.Now()
+	return l.Interface.Update(*record)
+}
+
+// Run calls run while this replica holds the lock. Standby replicas block until they are elected. Losing the
+// lock exits the process so no work is done twice. run must return once the stop channel passed to it is
+// closed, which happens when stopCh is closed or the lock is no longer renewed.
+// Standbys return as soon as stopCh is closed, the leader returns once run has returned and the lock is released.
+func Run(stopCh <-chan struct{}, lock resourcelock.Interface, leaseDuration, renewDeadline, retryPeriod time.Duration, run func(stopCh <-chan struct{})) {
+	elected := make(chan struct{})
+	stopped := make(chan struct{})
+	leaderLock := &releasableLock{Interface: lock}
+
+	go leaderelection.RunOrDie(leaderelection.LeaderElectionConfig{
+		Lock:          leaderLock,
+		LeaseDuration: leaseDuration,
+		RenewDeadline: renewDeadline,
+		RetryPeriod:   retryPeriod,
+		Callbacks: leaderelection.LeaderCallbacks{
+			OnStartedLeading: func(stop <-chan struct{}) {
+				log.Printf("Elected leader as %s", lock.Identity())
+				close(elected)
+
+				runStopCh := make(chan struct{})
+				go func() {
+					select {
+					case <-stopCh:
+					case <-stop:
+					}
+					close(runStopCh)
+				}()
+				run(runStopCh)
+
+				if err := leaderLock.release(); err != nil {
+					log.Printf("Error releasing leader lock %s: %s", lock.Describe(), err)
+				} else {
+					log.Printf("Released leader lock %s", lock.Describe())
+				}
+				close(stopped)
+			},
+			OnStoppedLeading: func() {
+				// The released lock can no longer be renewed
+				select {
+				case <-stopCh:
+					return
+				default:
+				}
+				log.Fatalf("Lost leader election as %s", lock.Identity())
+			},
+			OnNewLeader: func(identity string) {
+				log.Printf("Current leader is %s", identity)
+			},
+		},
+	})
+
+	<-stopCh
+	select {
+	case <-elected:
+		<-stopped
+	default:
+	}
+}
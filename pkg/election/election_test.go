@@ -0,0 +1,71 @@
+package election
+
+import (
+	"testing"
+
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+)
+
+// fakeLock keeps the record in memory
+type fakeLock struct {
+	identity string
+	record   *resourcelock.LeaderElectionRecord
+}
+
+func (l *fakeLock) Get() (*resourcelock.LeaderElectionRecord, error) {
+	record := *l.record
+	return &record, nil
+}
+
+func (l *fakeLock) Create(record resourcelock.LeaderElectionRecord) error {
+	l.record = &record
+	return nil
+}
+
+func (l *fakeLock) Update(record resourcelock.LeaderElectionRecord) error {
+	l.record = &record
+	return nil
+}
+
+func (l *fakeLock) RecordEvent(string) {}
+
+func (l *fakeLock) Identity() string {
+	return l.identity
+}
+
+func (l *fakeLock) Describe() string {
+	return "test/lock"
+}
+
+func TestRelease(t *testing.T) {
+	tests := []struct {
+		name       string
+		holder     string
+		wantHolder string
+	}{
+		{name: "held by this replica", holder: "a", wantHolder: ""},
+		{name: "held by another replica", holder: "b", wantHolder: "b"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := &fakeLock{identity: "a", record: &resourcelock.LeaderElectionRecord{HolderIdentity: tt.holder, LeaseDurationSeconds: 15}}
+			lock := &releasableLock{Interface: fake}
+
+			if err := lock.release(); err != nil {
+				t.Fatalf("release() = %s", err)
+			}
+			if fake.record.HolderIdentity != tt.wantHolder {
+				t.Errorf("holder = %q, want %q", fake.record.HolderIdentity, tt.wantHolder)
+			}
+
+			// The elector must not be able to take the lock back
+			if err := lock.Update(resourcelock.LeaderElectionRecord{HolderIdentity: "a"}); err == nil {
+				t.Errorf("Update() after release succeeded")
+			}
+			if fake.record.HolderIdentity != tt.wantHolder {
+				t.Errorf("holder after update = %q, want %q", fake.record.HolderIdentity, tt.wantHolder)
+			}
+		})
+	}
+}
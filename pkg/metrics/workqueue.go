@@ -0,0 +1,70 @@
+// Package metrics exports the client-go workqueue metrics to prometheus.
+//
+// client-go only exposes an interface for workqueue metrics, a provider has to be set before
+// any named queue is created for the metrics to be recorded.
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/client-go/util/workqueue"
+)
+
+var (
+	depth = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Subsystem: "workqueue",
+		Name:      "depth",
+		Help:      "Current depth of the workqueue",
+	}, []string{"name"})
+
+	adds = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Subsystem: "workqueue",
+		Name:      "adds_total",
+		Help:      "Total number of adds handled by the workqueue",
+	}, []string{"name"})
+
+	latency = prometheus.NewSummaryVec(prometheus.SummaryOpts{
+		Subsystem: "workqueue",
+		Name:      "queue_latency_microseconds",
+		Help:      "How long an item stays in the workqueue before being requested",
+	}, []string{"name"})
+
+	workDuration = prometheus.NewSummaryVec(prometheus.SummaryOpts{
+		Subsystem: "workqueue",
+		Name:      "work_duration_microseconds",
+		Help:      "How long processing an item from the workqueue takes",
+	}, []string{"name"})
+
+	retries = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Subsystem: "workqueue",
+		Name:      "retries_total",
+		Help:      "Total number of retries handled by the workqueue",
+	}, []string{"name"})
+)
+
+func init() {
+	prometheus.MustRegister(depth, adds, latency, workDuration, retries)
+	workqueue.SetProvider(workqueueMetricsProvider{})
+}
+
+// workqueueMetricsProvider implements workqueue.MetricsProvider, every metric is labeled with the queue name
+type workqueueMetricsProvider struct{}
+
+func (workqueueMetricsProvider) NewDepthMetric(name string) workqueue.GaugeMetric {
+	return depth.WithLabelValues(name)
+}
+
+func (workqueueMetricsProvider) NewAddsMetric(name string) workqueue.CounterMetric {
+	return adds.WithLabelValues(name)
+}
+
+func (workqueueMetricsProvider) NewLatencyMetric(name string) workqueue.SummaryMetric {
+	return latency.WithLabelValues(name)
+}
+
+func (workqueueMetricsProvider) NewWorkDurationMetric(name string) workqueue.SummaryMetric {
+	return workDuration.WithLabelValues(name)
+}
+
+func (workqueueMetricsProvider) NewRetriesMetric(name string) workqueue.CounterMetric {
+	return retries.WithLabelValues(name)
+}